@@ -0,0 +1,194 @@
+// Package ast describes the abstract syntax tree of mgol
+// programs, built by the parser for the passes that come
+// after it
+package ast
+
+import "mgol-go/src/source"
+
+// Type is the type of a variable or of an expression
+type Type string
+
+const (
+	Integer Type = "inteiro"
+	Real    Type = "real"
+	Literal Type = "literal"
+)
+
+// Node is a node of the tree, which knows
+// the span of the program it was read from
+type Node interface {
+	Span() source.Span
+}
+
+// Stmt is a command of the program
+type Stmt interface {
+	Node
+	stmtNode()
+}
+
+// Expr is a value used by a command
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// Base holds the span of a node
+type Base struct {
+	Location source.Span
+}
+
+func (b Base) Span() source.Span {
+	return b.Location
+}
+
+// At returns the Base of a node read from span
+func At(span source.Span) Base {
+	return Base{Location: span}
+}
+
+// Program is the whole source program, the
+// variables declared and the commands run
+type Program struct {
+	Base
+	Declarations []*VarDecl
+	Body         []Stmt
+}
+
+// VarDecl declares the variable Name of type Type, which
+// starts with Value when the declaration gives it one.
+// Arrays have the size of each dimension on Sizes
+type VarDecl struct {
+	Base
+	Type  Type
+	Name  *Ident
+	Value Expr
+	Sizes []string
+}
+
+// Read is leia, which reads Target, a variable
+// or an element of an array, from the input
+type Read struct {
+	Base
+	Target Expr
+}
+
+// Write is escreva, which writes Value to the output
+type Write struct {
+	Base
+	Value Expr
+}
+
+// Assign stores Value on Target, a variable
+// or an element of an array
+type Assign struct {
+	Base
+	Target Expr
+	Value  Expr
+}
+
+// If runs Body when Cond holds and, when it
+// doesn't, the body of Else, if there is one
+type If struct {
+	Base
+	Cond Expr
+	Body []Stmt
+	Else *Else
+}
+
+// Else is the senao of an If
+type Else struct {
+	Base
+	Body []Stmt
+}
+
+// Repeat is repita, which runs Body while Cond holds
+type Repeat struct {
+	Base
+	Cond Expr
+	Body []Stmt
+}
+
+// BinaryExpr is an arithmetic operation, where
+// Operator is one of +, -, * and /
+type BinaryExpr struct {
+	Base
+	Left     Expr
+	Operator string
+	Right    Expr
+}
+
+// UnaryExpr applies Operator, one of +, - and nao,
+// to Operand
+type UnaryExpr struct {
+	Base
+	Operator string
+	Operand  Expr
+}
+
+// RelExpr compares Left and Right, where Operator
+// is one of <, >, >=, <=, = and <>
+type RelExpr struct {
+	Base
+	Left     Expr
+	Operator string
+	Right    Expr
+}
+
+// LogicalExpr joins two conditions, where Operator is e
+// or ou. Right is only evaluated when Left doesn't
+// decide the result
+type LogicalExpr struct {
+	Base
+	Left     Expr
+	Operator string
+	Right    Expr
+}
+
+// IndexExpr is the element of Array at Indexes,
+// one for each dimension of the array
+type IndexExpr struct {
+	Base
+	Array   *Ident
+	Indexes []Expr
+}
+
+// Ident is a variable used by the program
+type Ident struct {
+	Base
+	Name string
+}
+
+// IntLit is an integer number, as it is written
+type IntLit struct {
+	Base
+	Text string
+}
+
+// RealLit is a real number, as it is written
+type RealLit struct {
+	Base
+	Text string
+}
+
+// StringLit is a literal, as it is written
+// on the program, quotes included
+type StringLit struct {
+	Base
+	Text string
+}
+
+func (*Read) stmtNode()   {}
+func (*Write) stmtNode()  {}
+func (*Assign) stmtNode() {}
+func (*If) stmtNode()     {}
+func (*Repeat) stmtNode() {}
+
+func (*BinaryExpr) exprNode()  {}
+func (*UnaryExpr) exprNode()   {}
+func (*RelExpr) exprNode()     {}
+func (*LogicalExpr) exprNode() {}
+func (*IndexExpr) exprNode()   {}
+func (*Ident) exprNode()       {}
+func (*IntLit) exprNode()      {}
+func (*RealLit) exprNode()     {}
+func (*StringLit) exprNode()   {}
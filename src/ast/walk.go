@@ -0,0 +1,74 @@
+package ast
+
+// Children returns the nodes right below
+// node, in the order they were written
+func Children(node Node) []Node {
+	children := []Node{}
+	add := func(nodes ...Node) {
+		children = append(children, nodes...)
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		for _, declaration := range n.Declarations {
+			add(declaration)
+		}
+		add(stmts(n.Body)...)
+	case *VarDecl:
+		add(n.Name)
+		if n.Value != nil {
+			add(n.Value)
+		}
+	case *Read:
+		add(n.Target)
+	case *Write:
+		add(n.Value)
+	case *Assign:
+		add(n.Target, n.Value)
+	case *If:
+		add(n.Cond)
+		add(stmts(n.Body)...)
+		if n.Else != nil {
+			add(n.Else)
+		}
+	case *Else:
+		add(stmts(n.Body)...)
+	case *Repeat:
+		add(n.Cond)
+		add(stmts(n.Body)...)
+	case *BinaryExpr:
+		add(n.Left, n.Right)
+	case *UnaryExpr:
+		add(n.Operand)
+	case *RelExpr:
+		add(n.Left, n.Right)
+	case *LogicalExpr:
+		add(n.Left, n.Right)
+	case *IndexExpr:
+		add(n.Array)
+		for _, index := range n.Indexes {
+			add(index)
+		}
+	}
+	return children
+}
+
+// Inspect visits the tree below node depth first, calling
+// visit on each node before its children. The children of
+// a node are skipped when visit returns false
+func Inspect(node Node, visit func(Node) bool) {
+	if node == nil || !visit(node) {
+		return
+	}
+	for _, child := range Children(node) {
+		Inspect(child, visit)
+	}
+}
+
+func stmts(body []Stmt) []Node {
+	nodes := make([]Node, 0, len(body))
+	for _, stmt := range body {
+		nodes = append(nodes, stmt)
+	}
+	return nodes
+}
@@ -0,0 +1,53 @@
+package ast
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	program := &Program{
+		Declarations: []*VarDecl{{Type: Integer, Name: &Ident{Name: "A"}}},
+		Body: []Stmt{
+			&Read{Target: &Ident{Name: "A"}},
+			&If{
+				Cond: &RelExpr{Left: &Ident{Name: "A"}, Operator: ">", Right: &IntLit{Text: "1"}},
+				Body: []Stmt{&Write{Value: &StringLit{Text: "\"grande\""}}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		skip          func(Node) bool
+		expectedNodes []string
+	}{
+		{
+			name:          "Every node",
+			skip:          func(Node) bool { return false },
+			expectedNodes: []string{"*ast.Program", "*ast.VarDecl", "*ast.Ident", "*ast.Read", "*ast.Ident", "*ast.If", "*ast.RelExpr", "*ast.Ident", "*ast.IntLit", "*ast.Write", "*ast.StringLit"},
+		},
+		{
+			name: "Skipping the children of if",
+			skip: func(node Node) bool {
+				_, isIf := node.(*If)
+				return isIf
+			},
+			expectedNodes: []string{"*ast.Program", "*ast.VarDecl", "*ast.Ident", "*ast.Read", "*ast.Ident", "*ast.If"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			visited := []string{}
+			Inspect(program, func(node Node) bool {
+				visited = append(visited, fmt.Sprintf("%T", node))
+				return !tc.skip(node)
+			})
+			r.Equal(tc.expectedNodes, visited)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"mgol-go/src/grammar"
+	"mgol-go/src/lexer"
+	"os"
+)
+
+const checkGrammarCommand = "check-grammar"
+
+// checkGrammar reports the conflicts of the tables of the
+// grammar and the rules and symbols that make no sense on
+// it, exiting with an error when anything is found
+func checkGrammar(args []string) {
+	flags := flag.NewFlagSet(checkGrammarCommand, flag.ExitOnError)
+	grammarFile := flags.String("grammar", grammarPath, "grammar to be checked")
+	methodName := flags.String("method", string(grammar.LALR), "how the lookaheads of the reductions are computed: slr or lalr")
+	flags.Parse(args)
+
+	method, err := grammar.ParseMethod(*methodName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report, err := grammar.Analyze(loadGrammar(*grammarFile), method, lexer.GetDefaultSpec().Terminals())
+	if err != nil {
+		log.Fatalf("Failed to analyze the grammar: %v", err)
+	}
+
+	report.Write(os.Stdout)
+	if report.HasProblems() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,45 @@
+package emit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteDOT writes the tree below root as a Graphviz graph
+// called name. Nodes with text show it under their label
+func WriteDOT(writer io.Writer, name string, root *Node) error {
+	output := bufio.NewWriter(writer)
+	fmt.Fprintf(output, "digraph %s {\n", quote(name))
+	fmt.Fprintf(output, "\tnode [shape=box, fontname=\"monospace\"];\n")
+
+	count := 0
+	var write func(node *Node) int
+	write = func(node *Node) int {
+		id := count
+		count++
+
+		label := node.Label
+		if node.Text != "" {
+			label += "\n" + node.Text
+		}
+		fmt.Fprintf(output, "\tn%d [label=%s];\n", id, quote(label))
+
+		for _, child := range node.Children {
+			childId := write(child)
+			fmt.Fprintf(output, "\tn%d -> n%d;\n", id, childId)
+		}
+		return id
+	}
+	write(root)
+
+	fmt.Fprintf(output, "}\n")
+	return output.Flush()
+}
+
+// quote returns text as a quoted string of DOT
+func quote(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + replacer.Replace(text) + `"`
+}
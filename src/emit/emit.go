@@ -0,0 +1,124 @@
+// Package emit writes the trees built by the parser, the
+// derivation of the program and its abstract syntax tree,
+// in formats other tools can show
+package emit
+
+import (
+	"fmt"
+	"mgol-go/src/ast"
+	"mgol-go/src/parser"
+	"mgol-go/src/source"
+)
+
+// Kind is which of the trees is written
+type Kind string
+
+const (
+	KindParseTree Kind = "parse-tree"
+	KindAST       Kind = "ast"
+)
+
+// Format is how the tree is written
+type Format string
+
+const (
+	FormatDOT  Format = "dot"
+	FormatJSON Format = "json"
+)
+
+var (
+	ErrorUnknownKind   = fmt.Errorf("unknown tree to emit, expected parse-tree or ast")
+	ErrorUnknownFormat = fmt.Errorf("unknown emit format, expected dot or json")
+)
+
+// ParseKind returns the Kind called name
+func ParseKind(name string) (Kind, error) {
+	switch kind := Kind(name); kind {
+	case KindParseTree, KindAST:
+		return kind, nil
+	}
+	return "", ErrorUnknownKind
+}
+
+// ParseFormat returns the Format called name
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case FormatDOT, FormatJSON:
+		return format, nil
+	}
+	return "", ErrorUnknownFormat
+}
+
+// Node is a node of either tree, as it is written. Label
+// names the symbol or the kind of the node and Text is
+// what was read for it, like a lexem or an operator
+type Node struct {
+	Label    string
+	Text     string
+	Span     source.Span
+	Children []*Node
+}
+
+// FromParseTree returns the nodes of a derivation
+func FromParseTree(tree *parser.ParseTree) *Node {
+	node := &Node{
+		Label: tree.Symbol,
+		Text:  tree.Lexem,
+		Span:  tree.Span,
+	}
+	for _, child := range tree.Children {
+		if child != nil {
+			node.Children = append(node.Children, FromParseTree(child))
+		}
+	}
+	return node
+}
+
+// FromAST returns the nodes of an abstract syntax tree
+func FromAST(tree ast.Node) *Node {
+	node := &Node{Span: tree.Span()}
+	switch n := tree.(type) {
+	case *ast.Program:
+		node.Label = "Program"
+	case *ast.VarDecl:
+		node.Label, node.Text = "VarDecl", string(n.Type)
+		for _, size := range n.Sizes {
+			node.Text += "[" + size + "]"
+		}
+	case *ast.Read:
+		node.Label = "Read"
+	case *ast.Write:
+		node.Label = "Write"
+	case *ast.Assign:
+		node.Label = "Assign"
+	case *ast.If:
+		node.Label = "If"
+	case *ast.Else:
+		node.Label = "Else"
+	case *ast.Repeat:
+		node.Label = "Repeat"
+	case *ast.BinaryExpr:
+		node.Label, node.Text = "BinaryExpr", n.Operator
+	case *ast.UnaryExpr:
+		node.Label, node.Text = "UnaryExpr", n.Operator
+	case *ast.RelExpr:
+		node.Label, node.Text = "RelExpr", n.Operator
+	case *ast.LogicalExpr:
+		node.Label, node.Text = "LogicalExpr", n.Operator
+	case *ast.IndexExpr:
+		node.Label = "IndexExpr"
+	case *ast.Ident:
+		node.Label, node.Text = "Ident", n.Name
+	case *ast.IntLit:
+		node.Label, node.Text = "IntLit", n.Text
+	case *ast.RealLit:
+		node.Label, node.Text = "RealLit", n.Text
+	case *ast.StringLit:
+		node.Label, node.Text = "StringLit", n.Text
+	}
+
+	for _, child := range ast.Children(tree) {
+		node.Children = append(node.Children, FromAST(child))
+	}
+	return node
+}
@@ -0,0 +1,127 @@
+package emit
+
+import (
+	"bytes"
+	"mgol-go/src/ast"
+	"mgol-go/src/parser"
+	"mgol-go/src/source"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func span(startColumn, endColumn int) source.Span {
+	return source.NewSpan(
+		source.Position{Line: 1, Column: startColumn, Offset: startColumn - 1},
+		source.Position{Line: 1, Column: endColumn, Offset: endColumn - 1},
+	)
+}
+
+func TestFromAST(t *testing.T) {
+	r := require.New(t)
+	program := &ast.Program{
+		Base: ast.At(span(1, 10)),
+		Body: []ast.Stmt{
+			&ast.Assign{
+				Base:   ast.At(span(1, 10)),
+				Target: &ast.Ident{Base: ast.At(span(1, 2)), Name: "A"},
+				Value: &ast.BinaryExpr{
+					Base:     ast.At(span(6, 10)),
+					Left:     &ast.Ident{Base: ast.At(span(6, 7)), Name: "A"},
+					Operator: "+",
+					Right:    &ast.IntLit{Base: ast.At(span(9, 10)), Text: "1"},
+				},
+			},
+		},
+	}
+
+	tree := FromAST(program)
+	r.Equal("Program", tree.Label)
+	r.Len(tree.Children, 1)
+
+	assign := tree.Children[0]
+	r.Equal("Assign", assign.Label)
+	r.Len(assign.Children, 2)
+	r.Equal(Node{Label: "Ident", Text: "A", Span: span(1, 2)}, *assign.Children[0])
+	r.Equal("BinaryExpr", assign.Children[1].Label)
+	r.Equal("+", assign.Children[1].Text)
+	r.Equal(span(6, 10), assign.Children[1].Span)
+}
+
+func TestFromParseTree(t *testing.T) {
+	r := require.New(t)
+	tree := FromParseTree(&parser.ParseTree{
+		Symbol: "OPRD",
+		Span:   span(1, 2),
+		Children: []*parser.ParseTree{
+			{Symbol: "id", Lexem: "A", Span: span(1, 2)},
+		},
+	})
+	r.Equal(&Node{
+		Label: "OPRD",
+		Span:  span(1, 2),
+		Children: []*Node{
+			{Label: "id", Text: "A", Span: span(1, 2)},
+		},
+	}, tree)
+}
+
+func TestWriteDOT(t *testing.T) {
+	r := require.New(t)
+	tree := &Node{
+		Label: "ES",
+		Children: []*Node{
+			{Label: "escreva", Text: "escreva"},
+			{Label: "lit", Text: `"A\n"`},
+		},
+	}
+
+	output := &bytes.Buffer{}
+	r.NoError(WriteDOT(output, "parse-tree", tree))
+	r.Equal(`digraph "parse-tree" {
+	node [shape=box, fontname="monospace"];
+	n0 [label="ES"];
+	n1 [label="escreva\nescreva"];
+	n0 -> n1;
+	n2 [label="lit\n\"A\\n\""];
+	n0 -> n2;
+}
+`, output.String())
+}
+
+func TestWriteJSON(t *testing.T) {
+	r := require.New(t)
+	tree := &Node{
+		Label:    "OPRD",
+		Span:     span(1, 2),
+		Children: []*Node{{Label: "id", Text: "A", Span: span(1, 2)}},
+	}
+
+	output := &bytes.Buffer{}
+	r.NoError(WriteJSON(output, tree))
+	r.JSONEq(`{
+		"label": "OPRD",
+		"span": {"start": {"line": 1, "column": 1, "offset": 0}, "end": {"line": 1, "column": 2, "offset": 1}},
+		"children": [{
+			"label": "id",
+			"text": "A",
+			"span": {"start": {"line": 1, "column": 1, "offset": 0}, "end": {"line": 1, "column": 2, "offset": 1}}
+		}]
+	}`, output.String())
+}
+
+func TestParse(t *testing.T) {
+	r := require.New(t)
+
+	kind, err := ParseKind("ast")
+	r.NoError(err)
+	r.Equal(KindAST, kind)
+	_, err = ParseKind("tokens")
+	r.ErrorIs(err, ErrorUnknownKind)
+
+	format, err := ParseFormat("json")
+	r.NoError(err)
+	r.Equal(FormatJSON, format)
+	_, err = ParseFormat("svg")
+	r.ErrorIs(err, ErrorUnknownFormat)
+}
@@ -0,0 +1,52 @@
+package emit
+
+import (
+	"encoding/json"
+	"io"
+	"mgol-go/src/source"
+)
+
+type jsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+type jsonSpan struct {
+	Start jsonPosition `json:"start"`
+	End   jsonPosition `json:"end"`
+}
+
+type jsonNode struct {
+	Label    string      `json:"label"`
+	Text     string      `json:"text,omitempty"`
+	Span     jsonSpan    `json:"span"`
+	Children []*jsonNode `json:"children,omitempty"`
+}
+
+func toJSONPosition(position source.Position) jsonPosition {
+	return jsonPosition{Line: position.Line, Column: position.Column, Offset: position.Offset}
+}
+
+func toJSONNode(node *Node) *jsonNode {
+	output := &jsonNode{
+		Label: node.Label,
+		Text:  node.Text,
+		Span: jsonSpan{
+			Start: toJSONPosition(node.Span.Start),
+			End:   toJSONPosition(node.Span.End),
+		},
+	}
+	for _, child := range node.Children {
+		output.Children = append(output.Children, toJSONNode(child))
+	}
+	return output
+}
+
+// WriteJSON writes the tree below root as nested
+// JSON objects, with the span of each node
+func WriteJSON(writer io.Writer, root *Node) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(toJSONNode(root))
+}
@@ -0,0 +1,156 @@
+package emit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"mgol-go/src/parser"
+	"strings"
+	"text/tabwriter"
+)
+
+// TraceFormat is how the steps of the parser are written
+type TraceFormat string
+
+const (
+	TraceText     TraceFormat = "text"
+	TraceJSON     TraceFormat = "json"
+	TraceMarkdown TraceFormat = "markdown"
+	TraceHTML     TraceFormat = "html"
+)
+
+var (
+	ErrorUnknownTraceFormat = fmt.Errorf("unknown trace format, expected text, json, markdown or html")
+)
+
+// traceHeader names the columns of the table
+var traceHeader = []string{"#", "Pilha", "Entrada", "Ação"}
+
+// ParseTraceFormat returns the TraceFormat called name
+func ParseTraceFormat(name string) (TraceFormat, error) {
+	switch format := TraceFormat(name); format {
+	case TraceText, TraceJSON, TraceMarkdown, TraceHTML:
+		return format, nil
+	}
+	return "", ErrorUnknownTraceFormat
+}
+
+// WriteTrace writes the steps as a table with the stack,
+// the input left and the action of each step
+func WriteTrace(writer io.Writer, format TraceFormat, steps []parser.TraceStep) error {
+	switch format {
+	case TraceJSON:
+		return writeTraceJSON(writer, steps)
+	case TraceMarkdown:
+		return writeTraceMarkdown(writer, steps)
+	case TraceHTML:
+		return writeTraceHTML(writer, steps)
+	}
+	return writeTraceText(writer, steps)
+}
+
+// traceRow returns the cells of the table for step
+func traceRow(idx int, step parser.TraceStep) []string {
+	return []string{
+		fmt.Sprint(idx + 1),
+		strings.Join(step.Stack, " "),
+		strings.Join(step.Input, " "),
+		step.Action,
+	}
+}
+
+func writeTraceText(writer io.Writer, steps []parser.TraceStep) error {
+	output := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(output, strings.Join(traceHeader, "\t"))
+	for idx, step := range steps {
+		fmt.Fprintln(output, strings.Join(traceRow(idx, step), "\t"))
+	}
+	return output.Flush()
+}
+
+type jsonStep struct {
+	Step   int      `json:"step"`
+	Kind   string   `json:"kind"`
+	Stack  []string `json:"stack"`
+	Input  []string `json:"input"`
+	Action string   `json:"action"`
+}
+
+func writeTraceJSON(writer io.Writer, steps []parser.TraceStep) error {
+	output := []jsonStep{}
+	for idx, step := range steps {
+		output = append(output, jsonStep{
+			Step:   idx + 1,
+			Kind:   string(step.Kind),
+			Stack:  step.Stack,
+			Input:  step.Input,
+			Action: step.Action,
+		})
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+func writeTraceMarkdown(writer io.Writer, steps []parser.TraceStep) error {
+	output := bufio.NewWriter(writer)
+	writeRow := func(cells []string) {
+		escaped := make([]string, len(cells))
+		for idx, cell := range cells {
+			escaped[idx] = escapeMarkdown(cell)
+		}
+		fmt.Fprintf(output, "| %s |\n", strings.Join(escaped, " | "))
+	}
+
+	writeRow(traceHeader)
+	fmt.Fprintf(output, "|%s\n", strings.Repeat(" --- |", len(traceHeader)))
+	for idx, step := range steps {
+		writeRow(traceRow(idx, step))
+	}
+	return output.Flush()
+}
+
+// escapeMarkdown keeps text, which may have
+// literals of the program, inside its cell
+func escapeMarkdown(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "<", "&lt;", "\n", " ")
+	return replacer.Replace(text)
+}
+
+// traceStyle is the style of the HTML page, where each
+// kind of step has its own color
+const traceStyle = `body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.75em; text-align: left; }
+td { font-family: monospace; white-space: pre; }
+th { background: #eee; }
+tr.reduce, tr.goto { background: #eef6ff; }
+tr.accept { background: #e9f9e9; }
+tr.error { background: #fdecea; }
+tr.recovery { background: #fff8e1; }
+`
+
+func writeTraceHTML(writer io.Writer, steps []parser.TraceStep) error {
+	output := bufio.NewWriter(writer)
+	fmt.Fprintf(output, "<!DOCTYPE html>\n<html lang=\"pt-BR\">\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(output, "<title>Análise sintática</title>\n<style>\n%s</style>\n</head>\n<body>\n", traceStyle)
+	fmt.Fprintf(output, "<table>\n<tr>")
+	for _, name := range traceHeader {
+		fmt.Fprintf(output, "<th>%s</th>", html.EscapeString(name))
+	}
+	fmt.Fprintf(output, "</tr>\n")
+
+	for idx, step := range steps {
+		fmt.Fprintf(output, "<tr class=\"%s\">", html.EscapeString(string(step.Kind)))
+		for _, cell := range traceRow(idx, step) {
+			fmt.Fprintf(output, "<td>%s</td>", html.EscapeString(cell))
+		}
+		fmt.Fprintf(output, "</tr>\n")
+	}
+
+	fmt.Fprintf(output, "</table>\n</body>\n</html>\n")
+	return output.Flush()
+}
@@ -0,0 +1,94 @@
+package emit
+
+import (
+	"bytes"
+	"mgol-go/src/parser"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var traceSteps = []parser.TraceStep{
+	{
+		Kind:   parser.TraceShift,
+		Stack:  []string{"0", "inicio", "2", "V", "3", "escreva", "12"},
+		Input:  []string{`"a|b"`, ";", "fim", "$"},
+		Action: "empilha 33",
+	},
+	{
+		Kind:   parser.TraceError,
+		Stack:  []string{"0", "inicio", "2", "V", "3", "escreva", "12", "lit", "33"},
+		Input:  []string{"fim", "$"},
+		Action: "erro: operação de entrada e saída inválida",
+	},
+}
+
+func TestWriteTrace(t *testing.T) {
+	testCases := []struct {
+		name           string
+		format         TraceFormat
+		expectedOutput string
+	}{
+		{
+			name:   "Text",
+			format: TraceText,
+			expectedOutput: `#  Pilha                             Entrada        Ação
+1  0 inicio 2 V 3 escreva 12         "a|b" ; fim $  empilha 33
+2  0 inicio 2 V 3 escreva 12 lit 33  fim $          erro: operação de entrada e saída inválida
+`,
+		},
+		{
+			name:   "Markdown",
+			format: TraceMarkdown,
+			expectedOutput: `| # | Pilha | Entrada | Ação |
+| --- | --- | --- | --- |
+| 1 | 0 inicio 2 V 3 escreva 12 | "a\|b" ; fim $ | empilha 33 |
+| 2 | 0 inicio 2 V 3 escreva 12 lit 33 | fim $ | erro: operação de entrada e saída inválida |
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			output := &bytes.Buffer{}
+			r.NoError(WriteTrace(output, tc.format, traceSteps))
+			r.Equal(tc.expectedOutput, output.String())
+		})
+	}
+}
+
+func TestWriteTraceJSON(t *testing.T) {
+	r := require.New(t)
+	output := &bytes.Buffer{}
+	r.NoError(WriteTrace(output, TraceJSON, traceSteps[:1]))
+	r.JSONEq(`[{
+		"step": 1,
+		"kind": "shift",
+		"stack": ["0", "inicio", "2", "V", "3", "escreva", "12"],
+		"input": ["\"a|b\"", ";", "fim", "$"],
+		"action": "empilha 33"
+	}]`, output.String())
+}
+
+func TestWriteTraceHTML(t *testing.T) {
+	r := require.New(t)
+	output := &bytes.Buffer{}
+	r.NoError(WriteTrace(output, TraceHTML, traceSteps))
+
+	page := output.String()
+	r.True(strings.HasPrefix(page, "<!DOCTYPE html>"))
+	r.Contains(page, "<style>")
+	r.Contains(page, `<tr class="shift"><td>1</td><td>0 inicio 2 V 3 escreva 12</td><td>&#34;a|b&#34; ; fim $</td><td>empilha 33</td></tr>`)
+	r.Contains(page, `<tr class="error">`)
+}
+
+func TestParseTraceFormat(t *testing.T) {
+	r := require.New(t)
+	format, err := ParseTraceFormat("markdown")
+	r.NoError(err)
+	r.Equal(TraceMarkdown, format)
+	_, err = ParseTraceFormat("csv")
+	r.ErrorIs(err, ErrorUnknownTraceFormat)
+}
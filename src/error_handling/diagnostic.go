@@ -0,0 +1,141 @@
+package errorhandling
+
+import (
+	"fmt"
+	"mgol-go/src/source"
+	"sort"
+	"strings"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityNote
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "erro"
+	case SeverityWarning:
+		return "aviso"
+	case SeverityNote:
+		return "nota"
+	}
+	return "desconhecido"
+}
+
+// Code identifies a kind of diagnostic. Codes are stable,
+// so tools can rely on them instead of on the messages.
+// Lexical codes start with L, syntactic ones with P and
+// semantic ones with S
+type Code string
+
+// Diagnostic is a problem found on the source program,
+// located by the span where it happened. Hint, when
+// given, tells the student how to fix it
+type Diagnostic struct {
+	Severity Severity
+	Code     Code
+	Span     source.Span
+	Message  string
+	Hint     string
+	Notes    []string
+}
+
+// NewDiagnostic returns an error diagnostic
+func NewDiagnostic(code Code, span source.Span, format string, args ...interface{}) Diagnostic {
+	return Diagnostic{
+		Severity: SeverityError,
+		Code:     code,
+		Span:     span,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// WithNote returns a copy of d with one more note
+func (d Diagnostic) WithNote(format string, args ...interface{}) Diagnostic {
+	d.Notes = append(append([]string{}, d.Notes...), fmt.Sprintf(format, args...))
+	return d
+}
+
+// WithHint returns a copy of d with the hint
+func (d Diagnostic) WithHint(format string, args ...interface{}) Diagnostic {
+	d.Hint = fmt.Sprintf(format, args...)
+	return d
+}
+
+// String returns d on a single line followed by its
+// notes. The hint is only shown by the Renderer
+func (d Diagnostic) String() string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "%v na linha %d coluna %d, %s", d.Severity, d.Span.Start.Line, d.Span.Start.Column, d.Message)
+	for _, note := range d.Notes {
+		fmt.Fprintf(&text, "\n\tnota: %s", note)
+	}
+	return text.String()
+}
+
+// Collector gathers the diagnostics reported
+// by every phase of the compilation
+type Collector struct {
+	diagnostics []Diagnostic
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		diagnostics: []Diagnostic{},
+	}
+}
+
+// Report adds d to the collected diagnostics
+func (c *Collector) Report(d Diagnostic) {
+	c.diagnostics = append(c.diagnostics, d)
+}
+
+// Diagnostics returns the diagnostics
+// in the order they were reported
+func (c *Collector) Diagnostics() []Diagnostic {
+	return append([]Diagnostic{}, c.diagnostics...)
+}
+
+// Sorted returns the diagnostics ordered by where they
+// happen on the source program. Diagnostics on the same
+// position keep the order they were reported
+func (c *Collector) Sorted() []Diagnostic {
+	sorted := c.Diagnostics()
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Span.Start.Offset < sorted[j].Span.Start.Offset
+	})
+	return sorted
+}
+
+// Filter returns the diagnostics that satisfy keep
+func (c *Collector) Filter(keep func(Diagnostic) bool) []Diagnostic {
+	filtered := []Diagnostic{}
+	for _, d := range c.diagnostics {
+		if keep(d) {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// Count returns how many diagnostics have severity
+func (c *Collector) Count(severity Severity) int {
+	return len(c.Filter(func(d Diagnostic) bool {
+		return d.Severity == severity
+	}))
+}
+
+// HasErrors returns whether any error was reported
+func (c *Collector) HasErrors() bool {
+	return c.Count(SeverityError) > 0
+}
+
+// Len returns how many diagnostics were reported
+func (c *Collector) Len() int {
+	return len(c.diagnostics)
+}
@@ -0,0 +1,69 @@
+package errorhandling
+
+import (
+	"mgol-go/src/source"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func spanAt(line, column, offset int) source.Span {
+	start := source.Position{Line: line, Column: column, Offset: offset}
+	return source.NewSpan(start, start)
+}
+
+func TestCollector(t *testing.T) {
+	r := require.New(t)
+
+	collector := NewCollector()
+	r.False(collector.HasErrors())
+
+	second := NewDiagnostic(UndeclaredVariableCode, spanAt(2, 1, 10), "variável '%s' não declarada", "B")
+	first := NewDiagnostic(InvalidWordCode, spanAt(1, 3, 2), "palavra %s inexistente na linguagem", "$")
+	warning := first
+	warning.Severity = SeverityWarning
+
+	collector.Report(second)
+	collector.Report(first)
+	collector.Report(warning)
+
+	r.Equal(3, collector.Len())
+	r.Equal(2, collector.Count(SeverityError))
+	r.Equal(1, collector.Count(SeverityWarning))
+	r.True(collector.HasErrors())
+	r.Equal([]Diagnostic{second, first, warning}, collector.Diagnostics())
+	r.Equal([]Diagnostic{first, warning, second}, collector.Sorted())
+}
+
+func TestDiagnosticString(t *testing.T) {
+	testCases := []struct {
+		name       string
+		diagnostic Diagnostic
+		expected   string
+	}{
+		{
+			name:       "Without notes",
+			diagnostic: NewDiagnostic(SyntaxErrorCode(3), spanAt(4, 7, 30), "%s", "expressão inválida"),
+			expected:   "erro na linha 4 coluna 7, expressão inválida",
+		},
+		{
+			name: "With notes",
+			diagnostic: NewDiagnostic(AssignmentTypeMismatchCode, spanAt(7, 1, 50), "tipos diferentes para a atribuição").
+				WithNote("'%s' foi declarada na linha %d coluna %d", "C", 4, 6),
+			expected: "erro na linha 7 coluna 1, tipos diferentes para a atribuição\n\tnota: 'C' foi declarada na linha 4 coluna 6",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			r.Equal(tc.expected, tc.diagnostic.String())
+		})
+	}
+}
+
+func TestSyntaxErrorCode(t *testing.T) {
+	r := require.New(t)
+	r.Equal(Code("P001"), SyntaxErrorCode(1))
+	r.Equal(Code("P012"), SyntaxErrorCode(12))
+}
@@ -0,0 +1,221 @@
+package errorhandling
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+)
+
+// Format is how the diagnostics are written
+// at the end of the compilation
+type Format string
+
+const (
+	FormatText  Format = "text"
+	FormatJSON  Format = "json"
+	FormatSARIF Format = "sarif"
+)
+
+var (
+	ErrorUnknownFormat = fmt.Errorf("unknown diagnostics format, expected text, json or sarif")
+)
+
+// ParseFormat returns the Format called name
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case FormatText, FormatJSON, FormatSARIF:
+		return format, nil
+	}
+	return "", ErrorUnknownFormat
+}
+
+// level returns the name of the severity on the
+// machine readable formats, the same used by SARIF
+func (s Severity) level() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+type jsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+type jsonSpan struct {
+	Start jsonPosition `json:"start"`
+	End   jsonPosition `json:"end"`
+}
+
+type jsonDiagnostic struct {
+	File     string   `json:"file"`
+	Severity string   `json:"severity"`
+	Code     Code     `json:"code"`
+	Message  string   `json:"message"`
+	Hint     string   `json:"hint,omitempty"`
+	Notes    []string `json:"notes,omitempty"`
+	Span     jsonSpan `json:"span"`
+}
+
+// WriteJSON writes diagnostics, found on fileName, as a JSON
+// array. Columns count characters and offsets count bytes
+func WriteJSON(writer io.Writer, fileName string, diagnostics []Diagnostic) error {
+	output := []jsonDiagnostic{}
+	for _, d := range diagnostics {
+		output = append(output, jsonDiagnostic{
+			File:     fileName,
+			Severity: d.Severity.level(),
+			Code:     d.Code,
+			Message:  d.Message,
+			Hint:     d.Hint,
+			Notes:    d.Notes,
+			Span: jsonSpan{
+				Start: jsonPosition{Line: d.Span.Start.Line, Column: d.Span.Start.Column, Offset: d.Span.Start.Offset},
+				End:   jsonPosition{Line: d.Span.End.Line, Column: d.Span.End.Column, Offset: d.Span.End.Offset},
+			},
+		})
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(output)
+}
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "mgol-go"
+	toolURI      = "https://github.com/MatheusNtg/mgol-go"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string           `json:"ruleId"`
+	Level      string           `json:"level"`
+	Message    sarifMessage     `json:"message"`
+	Locations  []sarifLocation  `json:"locations"`
+	Properties *sarifProperties `json:"properties,omitempty"`
+}
+
+type sarifProperties struct {
+	Hint  string   `json:"hint,omitempty"`
+	Notes []string `json:"notes,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+	ByteOffset  int `json:"byteOffset"`
+	ByteLength  int `json:"byteLength"`
+}
+
+// WriteSARIF writes diagnostics, found on fileName, as a
+// SARIF 2.1.0 log with a single run, so they can be shown
+// by code scanning tools. The error codes are the rule ids
+func WriteSARIF(writer io.Writer, fileName string, diagnostics []Diagnostic) error {
+	codes := map[Code]bool{}
+	results := []sarifResult{}
+	for _, d := range diagnostics {
+		codes[d.Code] = true
+
+		result := sarifResult{
+			RuleID:  string(d.Code),
+			Level:   d.Severity.level(),
+			Message: sarifMessage{Text: d.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(fileName)},
+					Region: sarifRegion{
+						StartLine:   d.Span.Start.Line,
+						StartColumn: d.Span.Start.Column,
+						EndLine:     d.Span.End.Line,
+						EndColumn:   d.Span.End.Column,
+						ByteOffset:  d.Span.Start.Offset,
+						ByteLength:  d.Span.End.Offset - d.Span.Start.Offset,
+					},
+				},
+			}},
+		}
+		if d.Hint != "" || len(d.Notes) > 0 {
+			result.Properties = &sarifProperties{Hint: d.Hint, Notes: d.Notes}
+		}
+		results = append(results, result)
+	}
+
+	rules := []sarifRule{}
+	for code := range codes {
+		rules = append(rules, sarifRule{ID: string(code)})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           toolName,
+				InformationURI: toolURI,
+				Rules:          rules,
+			}},
+			ColumnKind: "unicodeCodePoints",
+			Results:    results,
+		}},
+	})
+}
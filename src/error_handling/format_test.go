@@ -0,0 +1,122 @@
+package errorhandling
+
+import (
+	"bytes"
+	"encoding/json"
+	"mgol-go/src/source"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sampleDiagnostics() []Diagnostic {
+	return []Diagnostic{
+		NewDiagnostic(InvalidNumberCode, source.NewSpan(
+			source.Position{Line: 5, Column: 4, Offset: 36},
+			source.Position{Line: 5, Column: 7, Offset: 39},
+		), "número %s inválido", "12.").WithHint("números são escritos como 12, 12.5 ou 1.2e3"),
+		NewDiagnostic(AssignmentTypeMismatchCode, source.NewSpan(
+			source.Position{Line: 7, Column: 1, Offset: 50},
+			source.Position{Line: 7, Column: 6, Offset: 55},
+		), "tipos diferentes para a atribuição").WithNote("uma nota"),
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	r := require.New(t)
+
+	for _, name := range []string{"text", "json", "sarif"} {
+		format, err := ParseFormat(name)
+		r.NoError(err)
+		r.Equal(Format(name), format)
+	}
+
+	_, err := ParseFormat("xml")
+	r.ErrorIs(err, ErrorUnknownFormat)
+}
+
+func TestWriteJSON(t *testing.T) {
+	r := require.New(t)
+
+	output := &bytes.Buffer{}
+	r.NoError(WriteJSON(output, "file.mgol", sampleDiagnostics()))
+
+	decoded := []map[string]interface{}{}
+	r.NoError(json.Unmarshal(output.Bytes(), &decoded))
+	r.Len(decoded, 2)
+
+	r.Equal("file.mgol", decoded[0]["file"])
+	r.Equal("error", decoded[0]["severity"])
+	r.Equal("L002", decoded[0]["code"])
+	r.Equal("número 12. inválido", decoded[0]["message"])
+	r.Equal("números são escritos como 12, 12.5 ou 1.2e3", decoded[0]["hint"])
+	r.Equal(map[string]interface{}{
+		"start": map[string]interface{}{"line": 5.0, "column": 4.0, "offset": 36.0},
+		"end":   map[string]interface{}{"line": 5.0, "column": 7.0, "offset": 39.0},
+	}, decoded[0]["span"])
+	r.Equal([]interface{}{"uma nota"}, decoded[1]["notes"])
+}
+
+func TestWriteJSONWithoutDiagnostics(t *testing.T) {
+	r := require.New(t)
+
+	output := &bytes.Buffer{}
+	r.NoError(WriteJSON(output, "file.mgol", nil))
+	r.JSONEq("[]", output.String())
+}
+
+func TestWriteSARIF(t *testing.T) {
+	r := require.New(t)
+
+	output := &bytes.Buffer{}
+	r.NoError(WriteSARIF(output, "file.mgol", sampleDiagnostics()))
+
+	decoded := sarifLog{}
+	r.NoError(json.Unmarshal(output.Bytes(), &decoded))
+	r.Equal("2.1.0", decoded.Version)
+	r.Len(decoded.Runs, 1)
+
+	run := decoded.Runs[0]
+	r.Equal("mgol-go", run.Tool.Driver.Name)
+	r.Equal([]sarifRule{{ID: "L002"}, {ID: "S002"}}, run.Tool.Driver.Rules)
+	r.Len(run.Results, 2)
+
+	result := run.Results[0]
+	r.Equal("L002", result.RuleID)
+	r.Equal("error", result.Level)
+	r.Equal("número 12. inválido", result.Message.Text)
+	r.Equal("file.mgol", result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	r.Equal(sarifRegion{
+		StartLine:   5,
+		StartColumn: 4,
+		EndLine:     5,
+		EndColumn:   7,
+		ByteOffset:  36,
+		ByteLength:  3,
+	}, result.Locations[0].PhysicalLocation.Region)
+	r.Equal(&sarifProperties{Hint: "números são escritos como 12, 12.5 ou 1.2e3"}, result.Properties)
+}
+
+func TestWriteWithoutEscapingHTML(t *testing.T) {
+	diagnostics := []Diagnostic{
+		NewDiagnostic(AssignmentTypeMismatchCode, source.NewSpan(
+			source.Position{Line: 1, Column: 3, Offset: 2},
+			source.Position{Line: 1, Column: 5, Offset: 4},
+		), "tipos diferentes para '<-'").WithHint("use <- com um valor do mesmo tipo & tente de novo"),
+	}
+
+	for name, write := range map[string]func(*bytes.Buffer) error{
+		"json":  func(output *bytes.Buffer) error { return WriteJSON(output, "file.mgol", diagnostics) },
+		"sarif": func(output *bytes.Buffer) error { return WriteSARIF(output, "file.mgol", diagnostics) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			output := &bytes.Buffer{}
+			r.NoError(write(output))
+			r.Contains(output.String(), "tipos diferentes para '<-'")
+			r.Contains(output.String(), "use <- com um valor do mesmo tipo & tente de novo")
+			r.NotContains(output.String(), `\u003c`)
+			r.NotContains(output.String(), `\u0026`)
+		})
+	}
+}
@@ -1,7 +1,7 @@
 package errorhandling
 
 import (
-	"log"
+	"mgol-go/src/source"
 	"strings"
 )
 
@@ -14,6 +14,15 @@ const (
 	InvalidWord
 )
 
+// Codes of the lexical errors
+const (
+	InvalidLiteralCode Code = "L001"
+	InvalidNumberCode  Code = "L002"
+	InvalidCommentCode Code = "L003"
+	InvalidWordCode    Code = "L004"
+	ReadFailureCode    Code = "L005"
+)
+
 func isInvalidNumber(lexem string) bool {
 	containsQuotation := strings.Contains(lexem, "\"")
 	containsBrackets := strings.Contains(lexem, "{")
@@ -59,17 +68,27 @@ func getErrorType(lexem string) LexicalErrorType {
 	return InvalidWord
 }
 
-func NewLexicalError(line, column int, lexem string) {
+// NewLexicalError returns the diagnostic for the invalid
+// lexem, where span is the character the scanner stopped at
+func NewLexicalError(span source.Span, lexem string) Diagnostic {
 	errorType := getErrorType(lexem)
 
 	switch errorType {
 	case InvalidLiteral:
-		log.Printf("erro na linha %d coluna %d, literal %s inválido", line, column, lexem)
+		return NewDiagnostic(InvalidLiteralCode, span, "literal %s inválido", lexem).
+			WithHint("feche o literal com \" na mesma linha em que ele começa")
 	case InvalidNumber:
-		log.Printf("erro na linha %d coluna %d, número %s inválido", line, column, lexem)
+		return NewDiagnostic(InvalidNumberCode, span, "número %s inválido", lexem).
+			WithHint("números são escritos como 12, 12.5 ou 1.2e3")
 	case InvalidComment:
-		log.Printf("erro na linha %d coluna %d, comentário %s inválido", line, column, lexem)
-	case InvalidWord:
-		log.Printf("erro na linha %d coluna %d, palavra %s inexistente na linguagem", line, column, lexem)
+		return NewDiagnostic(InvalidCommentCode, span, "comentário %s inválido", lexem).
+			WithHint("feche o comentário com } na mesma linha em que ele começa")
 	}
+	return NewDiagnostic(InvalidWordCode, span, "palavra %s inexistente na linguagem", lexem)
+}
+
+// NewReadError returns the diagnostic for the source
+// program that failed to be read with err at span
+func NewReadError(span source.Span, err error) Diagnostic {
+	return NewDiagnostic(ReadFailureCode, span, "falha ao ler o programa: %v", err)
 }
@@ -0,0 +1,142 @@
+package errorhandling
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+)
+
+var (
+	severityStyles = map[Severity]*pterm.Style{
+		SeverityError:   pterm.NewStyle(pterm.FgLightRed, pterm.Bold),
+		SeverityWarning: pterm.NewStyle(pterm.FgLightYellow, pterm.Bold),
+		SeverityNote:    pterm.NewStyle(pterm.FgLightCyan, pterm.Bold),
+	}
+	gutterStyle  = pterm.NewStyle(pterm.FgLightBlue, pterm.Bold)
+	messageStyle = pterm.NewStyle(pterm.Bold)
+	hintStyle    = pterm.NewStyle(pterm.FgLightGreen)
+)
+
+// Renderer writes diagnostics the way a student reads them,
+// with the line of the source program where each one happened
+// and a caret under the offending characters:
+//
+//	erro[L002]: número 12. inválido
+//	 --> programa.mgol:5:7
+//	  |
+//	5 | A<-12.;
+//	  |       ^
+//	  = dica: números são escritos como 12, 12.5 ou 1.2e3
+type Renderer struct {
+	writer   io.Writer
+	fileName string
+	lines    []string
+	color    bool
+}
+
+// NewRenderer returns a Renderer that writes to writer the
+// diagnostics about program, the contents of fileName. Colors
+// are only used when color is true
+func NewRenderer(writer io.Writer, fileName string, program []byte, color bool) *Renderer {
+	lines := strings.Split(string(program), "\n")
+	for idx, line := range lines {
+		lines[idx] = strings.TrimSuffix(line, "\r")
+	}
+	return &Renderer{
+		writer:   writer,
+		fileName: fileName,
+		lines:    lines,
+		color:    color,
+	}
+}
+
+// IsTerminal returns whether file is a terminal,
+// where the diagnostics can be colored
+func IsTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func (r *Renderer) paint(style *pterm.Style, text string) string {
+	if !r.color {
+		return text
+	}
+	return style.Sprint(text)
+}
+
+// Render writes d along with an excerpt of the source program
+func (r *Renderer) Render(d Diagnostic) {
+	start := d.Span.Start
+	gutter := strings.Repeat(" ", len(strconv.Itoa(start.Line)))
+	bar := r.paint(gutterStyle, "|")
+
+	fmt.Fprintf(r.writer, "%s: %s\n",
+		r.paint(severityStyles[d.Severity], fmt.Sprintf("%v[%s]", d.Severity, d.Code)),
+		r.paint(messageStyle, d.Message))
+	fmt.Fprintf(r.writer, "%s%s %s:%d:%d\n", gutter, r.paint(gutterStyle, "-->"), r.fileName, start.Line, start.Column)
+
+	if start.Line >= 1 && start.Line <= len(r.lines) {
+		line := r.lines[start.Line-1]
+		fmt.Fprintf(r.writer, "%s %s\n", gutter, bar)
+		fmt.Fprintf(r.writer, "%s %s %s\n", r.paint(gutterStyle, strconv.Itoa(start.Line)), bar, line)
+		fmt.Fprintf(r.writer, "%s %s %s\n", gutter, bar, r.paint(severityStyles[d.Severity], underline(line, d)))
+	}
+
+	if d.Hint != "" {
+		fmt.Fprintf(r.writer, "%s %s %s\n", gutter, r.paint(gutterStyle, "="), r.paint(hintStyle, "dica: "+d.Hint))
+	}
+	for _, note := range d.Notes {
+		fmt.Fprintf(r.writer, "%s %s nota: %s\n", gutter, r.paint(gutterStyle, "="), note)
+	}
+	fmt.Fprintln(r.writer)
+}
+
+// RenderAll writes every diagnostic of diagnostics
+func (r *Renderer) RenderAll(diagnostics []Diagnostic) {
+	for _, d := range diagnostics {
+		r.Render(d)
+	}
+}
+
+// underline returns the carets that go under the characters
+// of line covered by the span of d. Spans that go past the
+// line are cut at its end, and empty spans, like the one of
+// the end of the file, get a single caret
+func underline(line string, d Diagnostic) string {
+	chars := []rune(line)
+	first := d.Span.Start.Column - 1
+	if first > len(chars) {
+		first = len(chars)
+	}
+
+	last := len(chars)
+	if d.Span.End.Line == d.Span.Start.Line {
+		last = d.Span.End.Column - 1
+	}
+	if last > len(chars) {
+		last = len(chars)
+	}
+	width := last - first
+	if width < 1 {
+		width = 1
+	}
+
+	// Tabs are kept, so the carets line up with the
+	// characters however wide the terminal draws them
+	var padding strings.Builder
+	for _, char := range chars[:first] {
+		if char == '\t' {
+			padding.WriteRune('\t')
+		} else {
+			padding.WriteRune(' ')
+		}
+	}
+	return padding.String() + strings.Repeat("^", width)
+}
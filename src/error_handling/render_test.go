@@ -0,0 +1,80 @@
+package errorhandling
+
+import (
+	"bytes"
+	"mgol-go/src/source"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	program := "inicio\nvarinicio\n\tinteiro A;\nvarfim;\nA<-12.;\nfim"
+
+	testCases := []struct {
+		name       string
+		diagnostic Diagnostic
+		expected   string
+	}{
+		{
+			name: "Hint",
+			diagnostic: NewDiagnostic(InvalidNumberCode, source.NewSpan(
+				source.Position{Line: 5, Column: 4, Offset: 36},
+				source.Position{Line: 5, Column: 7, Offset: 39},
+			), "número %s inválido", "12.").WithHint("números são escritos como 12, 12.5 ou 1.2e3"),
+			expected: "erro[L002]: número 12. inválido\n" +
+				" --> file.mgol:5:4\n" +
+				"  |\n" +
+				"5 | A<-12.;\n" +
+				"  |    ^^^\n" +
+				"  = dica: números são escritos como 12, 12.5 ou 1.2e3\n\n",
+		},
+		{
+			name: "Tabs and notes",
+			diagnostic: NewDiagnostic(UndeclaredVariableCode, source.NewSpan(
+				source.Position{Line: 3, Column: 10, Offset: 26},
+				source.Position{Line: 3, Column: 11, Offset: 27},
+			), "variável '%s' não declarada", "A").WithNote("uma nota"),
+			expected: "erro[S001]: variável 'A' não declarada\n" +
+				" --> file.mgol:3:10\n" +
+				"  |\n" +
+				"3 | \tinteiro A;\n" +
+				"  | \t        ^\n" +
+				"  = nota: uma nota\n\n",
+		},
+		{
+			name: "Span across lines",
+			diagnostic: NewDiagnostic(SyntaxErrorCode(2), source.NewSpan(
+				source.Position{Line: 2, Column: 1, Offset: 7},
+				source.Position{Line: 4, Column: 8, Offset: 35},
+			), "declaração de variáveis mal formada"),
+			expected: "erro[P002]: declaração de variáveis mal formada\n" +
+				" --> file.mgol:2:1\n" +
+				"  |\n" +
+				"2 | varinicio\n" +
+				"  | ^^^^^^^^^\n\n",
+		},
+		{
+			name: "End of file",
+			diagnostic: NewDiagnostic(SyntaxErrorCode(1), source.NewSpan(
+				source.Position{Line: 6, Column: 4, Offset: 47},
+				source.Position{Line: 6, Column: 4, Offset: 47},
+			), "token inesperado"),
+			expected: "erro[P001]: token inesperado\n" +
+				" --> file.mgol:6:4\n" +
+				"  |\n" +
+				"6 | fim\n" +
+				"  |    ^\n\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			output := &bytes.Buffer{}
+			NewRenderer(output, "file.mgol", []byte(program), false).Render(tc.diagnostic)
+			r.Equal(tc.expected, output.String())
+		})
+	}
+}
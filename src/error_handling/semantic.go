@@ -0,0 +1,12 @@
+package errorhandling
+
+// Codes of the semantic errors
+const (
+	UndeclaredVariableCode     Code = "S001"
+	AssignmentTypeMismatchCode Code = "S002"
+	OperandTypeMismatchCode    Code = "S003"
+	ConditionTypeMismatchCode  Code = "S004"
+	IndexCountMismatchCode     Code = "S005"
+	IndexTypeMismatchCode      Code = "S006"
+	InvalidArraySizeCode       Code = "S007"
+)
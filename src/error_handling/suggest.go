@@ -0,0 +1,76 @@
+package errorhandling
+
+// Suggest returns the candidate closest to word, when it is
+// close enough for word to be a typo of it. Ties are won by
+// the candidate that comes first
+func Suggest(word string, candidates []string) (string, bool) {
+	// Short words need to be closer, or
+	// every word would look like a typo
+	limit := len([]rune(word)) / 3
+	if limit < 1 {
+		limit = 1
+	}
+
+	best := ""
+	bestDistance := limit + 1
+	for _, candidate := range candidates {
+		if candidate == word {
+			continue
+		}
+		// A typo keeps something of the word that was meant,
+		// so x is not taken as a typo of the reserved word e
+		distance := editDistance(word, candidate)
+		if distance >= len([]rune(word)) || distance >= len([]rune(candidate)) {
+			continue
+		}
+		if distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	return best, best != ""
+}
+
+// editDistance returns how many characters must be inserted,
+// removed, replaced or swapped with their neighbour to turn a
+// into b, which is the optimal string alignment distance
+func editDistance(a, b string) int {
+	first, second := []rune(a), []rune(b)
+
+	distances := make([][]int, len(first)+1)
+	for i := range distances {
+		distances[i] = make([]int, len(second)+1)
+		distances[i][0] = i
+	}
+	for j := range distances[0] {
+		distances[0][j] = j
+	}
+
+	for i := 1; i <= len(first); i++ {
+		for j := 1; j <= len(second); j++ {
+			cost := 1
+			if first[i-1] == second[j-1] {
+				cost = 0
+			}
+			distances[i][j] = minimum(
+				distances[i-1][j]+1,
+				distances[i][j-1]+1,
+				distances[i-1][j-1]+cost,
+			)
+			if i > 1 && j > 1 && first[i-1] == second[j-2] && first[i-2] == second[j-1] {
+				distances[i][j] = minimum(distances[i][j], distances[i-2][j-2]+1)
+			}
+		}
+	}
+	return distances[len(first)][len(second)]
+}
+
+func minimum(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
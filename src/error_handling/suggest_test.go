@@ -0,0 +1,91 @@
+package errorhandling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditDistance(t *testing.T) {
+	testCases := []struct {
+		first            string
+		second           string
+		expectedDistance int
+	}{
+		{first: "escreva", second: "escreva", expectedDistance: 0},
+		{first: "escrva", second: "escreva", expectedDistance: 1},
+		{first: "fimsee", second: "fimse", expectedDistance: 1},
+		{first: "enatao", second: "entao", expectedDistance: 1},
+		{first: "etnao", second: "entao", expectedDistance: 1},
+		{first: "médai", second: "média", expectedDistance: 1},
+		{first: "", second: "fim", expectedDistance: 3},
+		{first: "leia", second: "real", expectedDistance: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.first+" "+tc.second, func(t *testing.T) {
+			r := require.New(t)
+			r.Equal(tc.expectedDistance, editDistance(tc.first, tc.second))
+			r.Equal(tc.expectedDistance, editDistance(tc.second, tc.first))
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"inicio", "varinicio", "escreva", "se", "fimse", "fim", "contador", "e"}
+
+	testCases := []struct {
+		name               string
+		word               string
+		expectedSuggestion string
+		expectedFound      bool
+	}{
+		{
+			name:               "Missing letter",
+			word:               "escrva",
+			expectedSuggestion: "escreva",
+			expectedFound:      true,
+		},
+		{
+			name:               "Closest candidate wins",
+			word:               "fimsee",
+			expectedSuggestion: "fimse",
+			expectedFound:      true,
+		},
+		{
+			name:               "Identifier",
+			word:               "contdor",
+			expectedSuggestion: "contador",
+			expectedFound:      true,
+		},
+		{
+			name:          "Too far from every candidate",
+			word:          "resultado",
+			expectedFound: false,
+		},
+		{
+			name:          "Short words need to be closer",
+			word:          "A",
+			expectedFound: false,
+		},
+		{
+			name:          "Nothing in common with the candidate",
+			word:          "x",
+			expectedFound: false,
+		},
+		{
+			name:          "The word itself is not a suggestion",
+			word:          "fim",
+			expectedFound: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			suggestion, found := Suggest(tc.word, candidates)
+			r.Equal(tc.expectedFound, found)
+			r.Equal(tc.expectedSuggestion, suggestion)
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package errorhandling
+
+import "fmt"
+
+// SyntaxErrorCode returns the code of the syntax errors
+// of category, the number of an error cell on the action
+// table, like P001 for e1
+func SyntaxErrorCode(category int) Code {
+	return Code(fmt.Sprintf("P%03d", category))
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"mgol-go/src/grammar"
+	"mgol-go/src/parser"
+	"os"
+)
+
+const genTablesCommand = "gen-tables"
+
+// genTables builds the action and goto tables of the
+// parser from its grammar and writes them where the
+// parser reads them from
+func genTables(args []string) {
+	flags := flag.NewFlagSet(genTablesCommand, flag.ExitOnError)
+	grammarFile := flags.String("grammar", grammarPath, "grammar the tables are built from")
+	actionFile := flags.String("action", actionTablePath, "where the action table is written")
+	gotoFile := flags.String("goto", gotoTablePath, "where the goto table is written")
+	methodName := flags.String("method", string(grammar.LALR), "how the lookaheads of the reductions are computed: slr or lalr")
+	flags.Parse(args)
+
+	method, err := grammar.ParseMethod(*methodName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tables, err := grammar.GenerateTables(loadGrammar(*grammarFile), method, parser.ErrorCategories)
+	if err != nil {
+		log.Fatalf("Failed to generate the tables: %v", err)
+	}
+
+	if len(tables.Conflicts) > 0 {
+		for _, conflict := range tables.Conflicts {
+			fmt.Fprintln(os.Stderr, "conflict on", conflict)
+		}
+		log.Fatalf("The grammar is not %s, the tables were not written. Run %s for the details", method, checkGrammarCommand)
+	}
+
+	writeTable(*actionFile, tables.WriteAction)
+	writeTable(*gotoFile, tables.WriteGoto)
+}
+
+// loadGrammar returns the rules of the grammar on path
+func loadGrammar(path string) []grammar.Rule {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	rules, err := grammar.LoadRules(file)
+	if err != nil {
+		log.Fatalf("Failed to load the grammar: %v", err)
+	}
+	return rules
+}
+
+func writeTable(path string, write func(writer io.Writer) error) {
+	file, err := os.Create(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	if err := write(file); err != nil {
+		log.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
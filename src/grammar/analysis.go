@@ -0,0 +1,285 @@
+package grammar
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ConflictReport explains a conflict of the action table:
+// the items of the state that ask for each action and the
+// shortest input that makes the parser reach it. Example ends
+// with the terminal read when the conflict happens
+type ConflictReport struct {
+	Conflict
+	Items   []string
+	Example []string
+}
+
+// Kind returns whether the conflict is
+// shift/reduce or reduce/reduce
+func (c ConflictReport) Kind() string {
+	for _, action := range c.Actions {
+		if action.Kind == Shift {
+			return "shift/reduce"
+		}
+	}
+	return "reduce/reduce"
+}
+
+// UndefinedSymbol is a symbol that is neither a
+// nonterminal nor a terminal the lexer produces
+type UndefinedSymbol struct {
+	Symbol string
+	Rules  []int
+}
+
+// Report is what the analysis of a grammar found wrong on it
+type Report struct {
+	Method                  Method
+	Conflicts               []ConflictReport
+	UnreachableNonterminals []string
+	UnproductiveRules       []Rule
+	UndefinedSymbols        []UndefinedSymbol
+}
+
+// Analyze looks for the problems of the grammar made of
+// rules: the conflicts of its tables built with method, the
+// nonterminals that can't be reached from the start symbol,
+// the rules that can't derive a string of terminals and, when
+// knownTerminals is not empty, the symbols that are neither
+// nonterminals nor one of knownTerminals
+func Analyze(rules []Rule, method Method, knownTerminals []string) (*Report, error) {
+	g, err := NewGrammar(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	automaton := NewAutomaton(g)
+	tables, err := automaton.Tables(method, ErrorCategories{})
+	if err != nil {
+		return nil, err
+	}
+
+	yields := g.shortestYields()
+	report := &Report{
+		Method:                  method,
+		UnreachableNonterminals: g.unreachableNonterminals(),
+		UnproductiveRules:       g.unproductiveRules(yields),
+		UndefinedSymbols:        g.undefinedSymbols(knownTerminals),
+	}
+
+	paths := automaton.shortestPaths(yields)
+	for _, conflict := range tables.Conflicts {
+		example := append(append([]string{}, paths[conflict.State]...), conflict.Terminal)
+		report.Conflicts = append(report.Conflicts, ConflictReport{
+			Conflict: conflict,
+			Items:    automaton.conflictItems(conflict),
+			Example:  example,
+		})
+	}
+	return report, nil
+}
+
+// HasProblems returns whether anything was found
+func (r *Report) HasProblems() bool {
+	return len(r.Conflicts) > 0 || len(r.UnreachableNonterminals) > 0 ||
+		len(r.UnproductiveRules) > 0 || len(r.UndefinedSymbols) > 0
+}
+
+// Write writes the report the way it is read by whoever
+// is changing the grammar
+func (r *Report) Write(writer io.Writer) {
+	for _, conflict := range r.Conflicts {
+		fmt.Fprintf(writer, "%s conflict on state %d reading %s\n", conflict.Kind(), conflict.State, conflict.Terminal)
+		fmt.Fprintln(writer, "  items:")
+		for _, item := range conflict.Items {
+			fmt.Fprintf(writer, "    %s\n", item)
+		}
+		actions := []string{}
+		for _, action := range conflict.Actions {
+			actions = append(actions, action.String())
+		}
+		fmt.Fprintf(writer, "  actions: %s, the table keeps %s\n", strings.Join(actions, ", "), actions[0])
+		prefix := conflict.Example[:len(conflict.Example)-1]
+		fmt.Fprintf(writer, "  example: %s\n", strings.TrimSpace(strings.Join(prefix, " ")+" . "+conflict.Terminal))
+	}
+	for _, nonterminal := range r.UnreachableNonterminals {
+		fmt.Fprintf(writer, "unreachable nonterminal %s\n", nonterminal)
+	}
+	for _, rule := range r.UnproductiveRules {
+		fmt.Fprintf(writer, "unproductive rule %d: %v\n", rule.Number, rule)
+	}
+	for _, undefined := range r.UndefinedSymbols {
+		fmt.Fprintf(writer, "undefined symbol %s used by the rules %v\n", undefined.Symbol, undefined.Rules)
+	}
+	if !r.HasProblems() {
+		fmt.Fprintf(writer, "the grammar is %s and has no problems\n", r.Method)
+	}
+}
+
+// shortestYields returns, for every symbol that derives a
+// string of terminals, the shortest of these strings.
+// Unproductive nonterminals are left out
+func (g *Grammar) shortestYields() map[string][]string {
+	yields := map[string][]string{}
+	for _, terminal := range g.terminals {
+		yields[terminal] = []string{terminal}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range g.rules {
+			yield, found := g.yieldOf(rule.Right, yields)
+			if !found {
+				continue
+			}
+			if current, derived := yields[rule.Left]; !derived || len(yield) < len(current) {
+				yields[rule.Left] = yield
+				changed = true
+			}
+		}
+	}
+	return yields
+}
+
+// yieldOf joins the yields of symbols, and returns
+// false if any of them is unproductive
+func (g *Grammar) yieldOf(symbols []string, yields map[string][]string) ([]string, bool) {
+	yield := []string{}
+	for _, symbol := range symbols {
+		symbolYield, found := yields[symbol]
+		if !found {
+			return nil, false
+		}
+		yield = append(yield, symbolYield...)
+	}
+	return yield, true
+}
+
+// unproductiveRules returns the rules that
+// can't derive a string of terminals
+func (g *Grammar) unproductiveRules(yields map[string][]string) []Rule {
+	rules := []Rule{}
+	for _, rule := range g.rules {
+		if _, found := g.yieldOf(rule.Right, yields); !found {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// unreachableNonterminals returns the nonterminals
+// that don't appear on any derivation of the start rule
+func (g *Grammar) unreachableNonterminals() []string {
+	reached := map[string]bool{g.rules[0].Left: true}
+	queue := []string{g.rules[0].Left}
+	for len(queue) > 0 {
+		nonterminal := queue[0]
+		queue = queue[1:]
+		for _, rule := range g.rulesOf(nonterminal) {
+			for _, symbol := range g.rules[rule].Right {
+				if !g.isTerminal[symbol] && !reached[symbol] {
+					reached[symbol] = true
+					queue = append(queue, symbol)
+				}
+			}
+		}
+	}
+
+	unreachable := []string{}
+	for _, nonterminal := range g.nonterminals {
+		if !reached[nonterminal] {
+			unreachable = append(unreachable, nonterminal)
+		}
+	}
+	return unreachable
+}
+
+// undefinedSymbols returns the terminals of the grammar
+// that are not one of knownTerminals
+func (g *Grammar) undefinedSymbols(knownTerminals []string) []UndefinedSymbol {
+	undefined := []UndefinedSymbol{}
+	if len(knownTerminals) == 0 {
+		return undefined
+	}
+
+	known := map[string]bool{EndOfInput: true, ErrorSymbol: true}
+	for _, terminal := range knownTerminals {
+		known[terminal] = true
+	}
+
+	for _, terminal := range g.terminals {
+		if known[terminal] {
+			continue
+		}
+		symbol := UndefinedSymbol{Symbol: terminal}
+		for _, rule := range g.rules {
+			for _, used := range rule.Right {
+				if used == terminal {
+					symbol.Rules = append(symbol.Rules, rule.Number)
+					break
+				}
+			}
+		}
+		undefined = append(undefined, symbol)
+	}
+	return undefined
+}
+
+// shortestPaths returns, for every state, the shortest string
+// of terminals that takes the parser from state 0 to it. Moving
+// on a nonterminal costs the length of its shortest yield
+func (a *Automaton) shortestPaths(yields map[string][]string) [][]string {
+	paths := make([][]string, len(a.states))
+	done := make([]bool, len(a.states))
+	paths[0] = []string{}
+
+	for {
+		current := -1
+		for idx, path := range paths {
+			if path != nil && !done[idx] && (current < 0 || len(path) < len(paths[current])) {
+				current = idx
+			}
+		}
+		if current < 0 {
+			return paths
+		}
+		done[current] = true
+
+		state := a.states[current]
+		for _, symbol := range state.Symbols {
+			yield, found := yields[symbol]
+			if !found {
+				continue
+			}
+			to := state.Transitions[symbol]
+			path := append(append([]string{}, paths[current]...), yield...)
+			if !done[to] && (paths[to] == nil || len(path) < len(paths[to])) {
+				paths[to] = path
+			}
+		}
+	}
+}
+
+// conflictItems returns the items of the state of conflict
+// that ask for its actions: the ones waiting for its terminal
+// and the complete ones of the rules it reduces
+func (a *Automaton) conflictItems(conflict Conflict) []string {
+	reduced := map[int]bool{}
+	for _, action := range conflict.Actions {
+		if action.Kind == Reduce {
+			reduced[action.Operand] = true
+		}
+	}
+
+	items := []string{}
+	for _, item := range a.states[conflict.State].Items {
+		symbol, found := a.nextSymbol(item)
+		if found && symbol == conflict.Terminal ||
+			!found && reduced[a.grammar.rules[item.Rule].Number] {
+			items = append(items, fmt.Sprintf("%s (rule %d)", a.ItemString(item), a.grammar.rules[item.Rule].Number))
+		}
+	}
+	return items
+}
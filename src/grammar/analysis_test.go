@@ -0,0 +1,101 @@
+package grammar
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeConflicts(t *testing.T) {
+	r := require.New(t)
+
+	report, err := Analyze(assignmentRules, SLR, nil)
+	r.NoError(err)
+	r.True(report.HasProblems())
+	r.Len(report.Conflicts, 1)
+
+	conflict := report.Conflicts[0]
+	r.Equal("shift/reduce", conflict.Kind())
+	r.Equal("=", conflict.Terminal)
+	r.Equal([]string{"S -> L . = R (rule 1)", "R -> L . (rule 5)"}, conflict.Items)
+	r.Equal([]string{"id", "="}, conflict.Example)
+
+	report, err = Analyze(assignmentRules, LALR, nil)
+	r.NoError(err)
+	r.False(report.HasProblems())
+}
+
+func TestAnalyzeReduceReduceConflict(t *testing.T) {
+	r := require.New(t)
+
+	rules := rulesOf(
+		"S' -> S",
+		"S -> ( A )",
+		"S -> ( B )",
+		"A -> x",
+		"B -> x",
+	)
+	report, err := Analyze(rules, LALR, nil)
+	r.NoError(err)
+	r.Len(report.Conflicts, 1)
+
+	conflict := report.Conflicts[0]
+	r.Equal("reduce/reduce", conflict.Kind())
+	r.Equal([]Action{{Kind: Reduce, Operand: 3}, {Kind: Reduce, Operand: 4}}, conflict.Actions)
+	r.Equal([]string{"A -> x . (rule 3)", "B -> x . (rule 4)"}, conflict.Items)
+	r.Equal([]string{"(", "x", ")"}, conflict.Example)
+}
+
+func TestAnalyzeUselessSymbols(t *testing.T) {
+	r := require.New(t)
+
+	rules := rulesOf(
+		"S' -> S",
+		"S -> a B",
+		"S -> a",
+		"B -> B b",
+		"C -> c",
+		"S -> error b",
+	)
+	// The error symbol is never undefined
+	report, err := Analyze(rules, LALR, []string{"a", "b"})
+	r.NoError(err)
+	r.True(report.HasProblems())
+	r.Empty(report.Conflicts)
+	r.Equal([]string{"C"}, report.UnreachableNonterminals)
+	r.Equal([]Rule{rules[1], rules[3]}, report.UnproductiveRules)
+	r.Equal([]UndefinedSymbol{{Symbol: "c", Rules: []int{4}}}, report.UndefinedSymbols)
+}
+
+func TestAnalyzeWithoutKnownTerminals(t *testing.T) {
+	r := require.New(t)
+
+	report, err := Analyze(expressionRules, LALR, nil)
+	r.NoError(err)
+	r.False(report.HasProblems())
+	r.Empty(report.UndefinedSymbols)
+}
+
+func TestReportWrite(t *testing.T) {
+	r := require.New(t)
+
+	report, err := Analyze(assignmentRules, SLR, nil)
+	r.NoError(err)
+
+	output := &bytes.Buffer{}
+	report.Write(output)
+	r.Equal("shift/reduce conflict on state 2 reading =\n"+
+		"  items:\n"+
+		"    S -> L . = R (rule 1)\n"+
+		"    R -> L . (rule 5)\n"+
+		"  actions: s6, r5, the table keeps s6\n"+
+		"  example: id . =\n", output.String())
+
+	report, err = Analyze(expressionRules, LALR, nil)
+	r.NoError(err)
+
+	output.Reset()
+	report.Write(output)
+	r.Equal("the grammar is lalr and has no problems\n", output.String())
+}
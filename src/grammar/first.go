@@ -0,0 +1,128 @@
+package grammar
+
+func (g *Grammar) computeNullable() {
+	g.nullable = map[string]bool{}
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range g.rules {
+			if g.nullable[rule.Left] {
+				continue
+			}
+			if g.sequenceIsNullable(rule.Right) {
+				g.nullable[rule.Left] = true
+				changed = true
+			}
+		}
+	}
+}
+
+func (g *Grammar) computeFirst() {
+	g.first = map[string]map[string]bool{}
+	for _, terminal := range g.terminals {
+		g.first[terminal] = map[string]bool{terminal: true}
+	}
+	for _, nonterminal := range g.nonterminals {
+		g.first[nonterminal] = map[string]bool{}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range g.rules {
+			for terminal := range g.firstOfSequence(rule.Right) {
+				if !g.first[rule.Left][terminal] {
+					g.first[rule.Left][terminal] = true
+					changed = true
+				}
+			}
+		}
+	}
+}
+
+func (g *Grammar) computeFollow() {
+	g.follow = map[string]map[string]bool{}
+	for _, nonterminal := range g.nonterminals {
+		g.follow[nonterminal] = map[string]bool{}
+	}
+	g.follow[g.rules[0].Left][EndOfInput] = true
+
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range g.rules {
+			for idx, symbol := range rule.Right {
+				if g.isTerminal[symbol] {
+					continue
+				}
+
+				rest := rule.Right[idx+1:]
+				follow := g.firstOfSequence(rest)
+				if g.sequenceIsNullable(rest) {
+					for terminal := range g.follow[rule.Left] {
+						follow[terminal] = true
+					}
+				}
+
+				for terminal := range follow {
+					if !g.follow[symbol][terminal] {
+						g.follow[symbol][terminal] = true
+						changed = true
+					}
+				}
+			}
+		}
+	}
+}
+
+// sequenceIsNullable returns whether every
+// symbol of symbols derives the empty string
+func (g *Grammar) sequenceIsNullable(symbols []string) bool {
+	for _, symbol := range symbols {
+		if !g.nullable[symbol] {
+			return false
+		}
+	}
+	return true
+}
+
+// firstOfSequence returns the terminals that
+// can start a string derived from symbols
+func (g *Grammar) firstOfSequence(symbols []string) map[string]bool {
+	first := map[string]bool{}
+	for _, symbol := range symbols {
+		for terminal := range g.first[symbol] {
+			first[terminal] = true
+		}
+		if !g.nullable[symbol] {
+			break
+		}
+	}
+	return first
+}
+
+// sortTerminals returns the terminals of set
+// in the order they appear on the grammar
+func (g *Grammar) sortTerminals(set map[string]bool) []string {
+	sorted := []string{}
+	for _, terminal := range g.terminals {
+		if set[terminal] {
+			sorted = append(sorted, terminal)
+		}
+	}
+	return sorted
+}
+
+// IsNullable returns whether symbol derives the empty string
+func (g *Grammar) IsNullable(symbol string) bool {
+	return g.nullable[symbol]
+}
+
+// First returns the terminals that can start a string
+// derived from symbol
+func (g *Grammar) First(symbol string) []string {
+	return g.sortTerminals(g.first[symbol])
+}
+
+// Follow returns the terminals that can come right
+// after nonterminal on a sentential form
+func (g *Grammar) Follow(nonterminal string) []string {
+	return g.sortTerminals(g.follow[nonterminal])
+}
@@ -0,0 +1,171 @@
+// Package grammar analyzes the context free grammar of mgol
+// and builds the LR tables the parser is driven by
+package grammar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+var (
+	ErrorEmptyGrammar    = fmt.Errorf("the grammar has no rules")
+	ErrorDuplicateRule   = fmt.Errorf("two rules have the same number")
+	ErrorInvalidStart    = fmt.Errorf("rule 0 must be the only rule of its nonterminal and derive a single symbol")
+	ErrorReservedSymbols = fmt.Errorf("the grammar uses a symbol reserved by the table generator")
+)
+
+// EndOfInput is the terminal read
+// after the end of the program
+const EndOfInput = "$"
+
+// ErrorSymbol is the terminal that stands for the input
+// skipped by the parser after a syntax error, like the
+// error token of yacc. Rules that use it, such as
+// CMD -> error pt_v, tell where the parser recovers
+const ErrorSymbol = "error"
+
+// Rule is a production of the grammar. Message and Code, on
+// the rules that use ErrorSymbol, describe the syntax errors
+// the parser recovers from with the rule and give them a
+// code of their own, whatever the state the error is on
+type Rule struct {
+	Number  int      `json:"rule_number"`
+	Left    string   `json:"left"`
+	Right   []string `json:"right"`
+	Message string   `json:"message,omitempty"`
+	Code    string   `json:"code,omitempty"`
+}
+
+func (r Rule) String() string {
+	return fmt.Sprintf("%s -> %v", r.Left, r.Right)
+}
+
+// HasErrorSymbol returns whether r is an error
+// production, with ErrorSymbol on its right side
+func (r Rule) HasErrorSymbol() bool {
+	for _, symbol := range r.Right {
+		if symbol == ErrorSymbol {
+			return true
+		}
+	}
+	return false
+}
+
+// Grammar is a set of rules whose rule 0 is the augmented
+// start rule, like P' -> P. Symbols that appear on the left
+// side of a rule are nonterminals and every other symbol is
+// a terminal
+type Grammar struct {
+	rules        []Rule
+	terminals    []string
+	nonterminals []string
+	isTerminal   map[string]bool
+	productions  map[string][]int
+	nullable     map[string]bool
+	first        map[string]map[string]bool
+	follow       map[string]map[string]bool
+}
+
+// LoadRules reads the rules of a grammar, a JSON array of
+// Rule, from reader
+func LoadRules(reader io.Reader) ([]Rule, error) {
+	rules := []Rule{}
+	if err := json.NewDecoder(reader).Decode(&rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// NewGrammar returns the grammar made of rules, which are
+// sorted by their numbers. Terminals and nonterminals keep the
+// order they first appear on the rules, with EndOfInput as the
+// last terminal
+func NewGrammar(rules []Rule) (*Grammar, error) {
+	if len(rules) == 0 {
+		return nil, ErrorEmptyGrammar
+	}
+
+	sorted := append([]Rule{}, rules...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Number < sorted[j].Number
+	})
+
+	g := &Grammar{
+		rules:       sorted,
+		isTerminal:  map[string]bool{},
+		productions: map[string][]int{},
+	}
+
+	for idx, rule := range sorted {
+		if idx > 0 && rule.Number == sorted[idx-1].Number {
+			return nil, fmt.Errorf("%w: %d", ErrorDuplicateRule, rule.Number)
+		}
+		if rule.Left == ErrorSymbol {
+			return nil, fmt.Errorf("%w: %s", ErrorReservedSymbols, rule.Left)
+		}
+		if _, found := g.productions[rule.Left]; !found {
+			g.nonterminals = append(g.nonterminals, rule.Left)
+		}
+		g.productions[rule.Left] = append(g.productions[rule.Left], idx)
+	}
+
+	start := sorted[0]
+	if start.Number != 0 || len(start.Right) != 1 || len(g.productions[start.Left]) != 1 {
+		return nil, ErrorInvalidStart
+	}
+
+	for _, rule := range sorted {
+		for _, symbol := range rule.Right {
+			if symbol == start.Left {
+				return nil, ErrorInvalidStart
+			}
+			if symbol == EndOfInput || symbol == propagated {
+				return nil, fmt.Errorf("%w: %s", ErrorReservedSymbols, symbol)
+			}
+			if _, found := g.productions[symbol]; !found && !g.isTerminal[symbol] {
+				g.isTerminal[symbol] = true
+				g.terminals = append(g.terminals, symbol)
+			}
+		}
+	}
+	g.isTerminal[EndOfInput] = true
+	g.terminals = append(g.terminals, EndOfInput)
+
+	g.computeNullable()
+	g.computeFirst()
+	g.computeFollow()
+	return g, nil
+}
+
+// Rules returns the rules sorted by their numbers
+func (g *Grammar) Rules() []Rule {
+	return g.rules
+}
+
+// Terminals returns the terminals, EndOfInput included
+func (g *Grammar) Terminals() []string {
+	return g.terminals
+}
+
+// Nonterminals returns the nonterminals, starting
+// with the one of the augmented start rule
+func (g *Grammar) Nonterminals() []string {
+	return g.nonterminals
+}
+
+// IsTerminal returns whether symbol is a terminal
+func (g *Grammar) IsTerminal(symbol string) bool {
+	return g.isTerminal[symbol]
+}
+
+// Start returns the symbol derived by the augmented start rule
+func (g *Grammar) Start() string {
+	return g.rules[0].Right[0]
+}
+
+// rulesOf returns the indexes of the rules of nonterminal
+func (g *Grammar) rulesOf(nonterminal string) []int {
+	return g.productions[nonterminal]
+}
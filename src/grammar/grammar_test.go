@@ -0,0 +1,191 @@
+package grammar
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rulesOf builds the rules written one per line,
+// like "E -> E + T", numbered in order
+func rulesOf(lines ...string) []Rule {
+	rules := []Rule{}
+	for idx, line := range lines {
+		parts := strings.SplitN(line, "->", 2)
+		rules = append(rules, Rule{
+			Number: idx,
+			Left:   strings.TrimSpace(parts[0]),
+			Right:  strings.Fields(parts[1]),
+		})
+	}
+	return rules
+}
+
+var (
+	expressionRules = rulesOf(
+		"E' -> E",
+		"E -> E + T",
+		"E -> T",
+		"T -> T * F",
+		"T -> F",
+		"F -> ( E )",
+		"F -> id",
+	)
+	// assignmentRules are LALR(1) but not SLR(1)
+	assignmentRules = rulesOf(
+		"S' -> S",
+		"S -> L = R",
+		"S -> R",
+		"L -> * R",
+		"L -> id",
+		"R -> L",
+	)
+	emptyRules = rulesOf(
+		"S' -> S",
+		"S -> A b",
+		"A -> a A",
+		"A -> ",
+	)
+)
+
+func TestLoadRules(t *testing.T) {
+	r := require.New(t)
+
+	rules, err := LoadRules(strings.NewReader(`[
+		{"rule_number": 0, "left": "P'", "right": ["P"]},
+		{"rule_number": 1, "left": "P", "right": ["inicio", "fim"]},
+		{"rule_number": 2, "left": "P", "right": ["error", "fim"], "message": "programa inválido", "code": "P010"}
+	]`))
+	r.NoError(err)
+	r.Equal([]Rule{
+		{Number: 0, Left: "P'", Right: []string{"P"}},
+		{Number: 1, Left: "P", Right: []string{"inicio", "fim"}},
+		{Number: 2, Left: "P", Right: []string{"error", "fim"}, Message: "programa inválido", Code: "P010"},
+	}, rules)
+	r.False(rules[1].HasErrorSymbol())
+	r.True(rules[2].HasErrorSymbol())
+
+	_, err = LoadRules(strings.NewReader(`{"rule_number": 0}`))
+	r.Error(err)
+}
+
+func TestNewGrammar(t *testing.T) {
+	r := require.New(t)
+
+	g, err := NewGrammar(expressionRules)
+	r.NoError(err)
+	r.Equal([]string{"E'", "E", "T", "F"}, g.Nonterminals())
+	r.Equal([]string{"+", "*", "(", ")", "id", EndOfInput}, g.Terminals())
+	r.Equal("E", g.Start())
+	r.True(g.IsTerminal("id"))
+	r.False(g.IsTerminal("T"))
+}
+
+func TestNewGrammarErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		rules         []Rule
+		expectedError error
+	}{
+		{
+			name:          "No rules",
+			rules:         []Rule{},
+			expectedError: ErrorEmptyGrammar,
+		},
+		{
+			name: "Duplicate rule numbers",
+			rules: []Rule{
+				{Number: 0, Left: "S'", Right: []string{"S"}},
+				{Number: 1, Left: "S", Right: []string{"a"}},
+				{Number: 1, Left: "S", Right: []string{"b"}},
+			},
+			expectedError: ErrorDuplicateRule,
+		},
+		{
+			name:          "Start rule with many symbols",
+			rules:         rulesOf("S' -> S a", "S -> a"),
+			expectedError: ErrorInvalidStart,
+		},
+		{
+			name:          "Start symbol used on a rule",
+			rules:         rulesOf("S' -> S", "S -> a S'"),
+			expectedError: ErrorInvalidStart,
+		},
+		{
+			name:          "Missing rule 0",
+			rules:         []Rule{{Number: 1, Left: "S", Right: []string{"a"}}},
+			expectedError: ErrorInvalidStart,
+		},
+		{
+			name:          "End of input on a rule",
+			rules:         rulesOf("S' -> S", "S -> a $"),
+			expectedError: ErrorReservedSymbols,
+		},
+		{
+			name:          "Rule of the error symbol",
+			rules:         rulesOf("S' -> S", "S -> error", "error -> a"),
+			expectedError: ErrorReservedSymbols,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewGrammar(tc.rules)
+			require.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestFirstAndFollow(t *testing.T) {
+	testCases := []struct {
+		name             string
+		rules            []Rule
+		symbol           string
+		expectedNullable bool
+		expectedFirst    []string
+		expectedFollow   []string
+	}{
+		{
+			name:           "Left recursive expression",
+			rules:          expressionRules,
+			symbol:         "E",
+			expectedFirst:  []string{"(", "id"},
+			expectedFollow: []string{"+", ")", EndOfInput},
+		},
+		{
+			name:           "Term",
+			rules:          expressionRules,
+			symbol:         "T",
+			expectedFirst:  []string{"(", "id"},
+			expectedFollow: []string{"+", "*", ")", EndOfInput},
+		},
+		{
+			name:             "Nullable nonterminal",
+			rules:            emptyRules,
+			symbol:           "A",
+			expectedNullable: true,
+			expectedFirst:    []string{"a"},
+			expectedFollow:   []string{"b"},
+		},
+		{
+			name:           "Nonterminal starting with a nullable one",
+			rules:          emptyRules,
+			symbol:         "S",
+			expectedFirst:  []string{"b", "a"},
+			expectedFollow: []string{EndOfInput},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			g, err := NewGrammar(tc.rules)
+			r.NoError(err)
+			r.Equal(tc.expectedNullable, g.IsNullable(tc.symbol))
+			r.Equal(tc.expectedFirst, g.First(tc.symbol))
+			r.Equal(tc.expectedFollow, g.Follow(tc.symbol))
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package grammar
+
+// propagated is the lookahead that marks, while the LALR(1)
+// lookaheads are computed, the ones that come from the kernel
+// item being closed instead of being generated spontaneously
+const propagated = "#"
+
+// lookaheadSets maps every item of a state to its lookaheads
+type lookaheadSets map[Item]map[string]bool
+
+// closureWithLookaheads returns the LR(1) closure of kernel,
+// where each item of kernel comes with its lookaheads
+func (a *Automaton) closureWithLookaheads(kernel []Item, lookaheads lookaheadSets) ([]Item, lookaheadSets) {
+	items := append([]Item{}, kernel...)
+	sets := lookaheadSets{}
+	for _, item := range kernel {
+		sets[item] = map[string]bool{}
+		for terminal := range lookaheads[item] {
+			sets[item][terminal] = true
+		}
+	}
+
+	queue := append([]Item{}, kernel...)
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+
+		symbol, found := a.nextSymbol(item)
+		if !found || a.grammar.IsTerminal(symbol) {
+			continue
+		}
+
+		rest := a.grammar.rules[item.Rule].Right[item.Dot+1:]
+		follow := a.grammar.firstOfSequence(rest)
+		if a.grammar.sequenceIsNullable(rest) {
+			for terminal := range sets[item] {
+				follow[terminal] = true
+			}
+		}
+
+		for _, rule := range a.grammar.rulesOf(symbol) {
+			next := Item{Rule: rule, Dot: 0}
+			set, exists := sets[next]
+			if !exists {
+				set = map[string]bool{}
+				sets[next] = set
+				items = append(items, next)
+			}
+
+			grew := false
+			for terminal := range follow {
+				if !set[terminal] {
+					set[terminal] = true
+					grew = true
+				}
+			}
+			if grew || !exists {
+				queue = append(queue, next)
+			}
+		}
+	}
+	return items, sets
+}
+
+// lalrLookaheads returns the LALR(1) lookaheads of the kernel
+// items of every state. Lookaheads are either generated
+// spontaneously inside a state or propagated from a kernel
+// item to the one it becomes after a transition, as done by
+// the algorithm of the dragon book
+func (a *Automaton) lalrLookaheads() []lookaheadSets {
+	type location struct {
+		state int
+		item  Item
+	}
+
+	lookaheads := make([]lookaheadSets, len(a.states))
+	for idx, state := range a.states {
+		lookaheads[idx] = lookaheadSets{}
+		for _, item := range state.Kernel {
+			lookaheads[idx][item] = map[string]bool{}
+		}
+	}
+	lookaheads[0][Item{Rule: 0, Dot: 0}][EndOfInput] = true
+
+	propagations := map[location][]location{}
+	sources := []location{}
+	for idx, state := range a.states {
+		for _, kernelItem := range state.Kernel {
+			from := location{state: idx, item: kernelItem}
+			sources = append(sources, from)
+
+			items, sets := a.closureWithLookaheads([]Item{kernelItem}, lookaheadSets{
+				kernelItem: {propagated: true},
+			})
+			for _, item := range items {
+				symbol, found := a.nextSymbol(item)
+				if !found {
+					continue
+				}
+				to := location{
+					state: state.Transitions[symbol],
+					item:  Item{Rule: item.Rule, Dot: item.Dot + 1},
+				}
+				for terminal := range sets[item] {
+					if terminal == propagated {
+						propagations[from] = append(propagations[from], to)
+					} else {
+						lookaheads[to.state][to.item][terminal] = true
+					}
+				}
+			}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, from := range sources {
+			for _, to := range propagations[from] {
+				for terminal := range lookaheads[from.state][from.item] {
+					if !lookaheads[to.state][to.item][terminal] {
+						lookaheads[to.state][to.item][terminal] = true
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	return lookaheads
+}
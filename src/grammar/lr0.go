@@ -0,0 +1,154 @@
+package grammar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Item is a rule with a dot on its right side, telling how
+// much of the rule was already read. Rule is the index of
+// the rule on Grammar.Rules
+type Item struct {
+	Rule int
+	Dot  int
+}
+
+// LR0State is a state of the LR(0) automaton. Items holds the
+// closure of the kernel, which comes first, and the symbols
+// leave the state in the order they appear after the dots
+type LR0State struct {
+	Kernel      []Item
+	Items       []Item
+	Symbols     []string
+	Transitions map[string]int
+}
+
+// Automaton is the canonical collection of LR(0) item sets
+// of a grammar. States are numbered in the order they are
+// found, so state 0 holds the augmented start rule
+type Automaton struct {
+	grammar *Grammar
+	states  []*LR0State
+}
+
+// NewAutomaton builds the LR(0) automaton of g
+func NewAutomaton(g *Grammar) *Automaton {
+	a := &Automaton{grammar: g}
+	indexes := map[string]int{}
+
+	addState := func(kernel []Item) int {
+		key := kernelKey(kernel)
+		if idx, found := indexes[key]; found {
+			return idx
+		}
+		idx := len(a.states)
+		indexes[key] = idx
+		a.states = append(a.states, &LR0State{
+			Kernel:      kernel,
+			Items:       a.closure(kernel),
+			Transitions: map[string]int{},
+		})
+		return idx
+	}
+
+	addState([]Item{{Rule: 0, Dot: 0}})
+	for idx := 0; idx < len(a.states); idx++ {
+		state := a.states[idx]
+		kernels := map[string][]Item{}
+		for _, item := range state.Items {
+			symbol, found := a.nextSymbol(item)
+			if !found {
+				continue
+			}
+			if _, seen := kernels[symbol]; !seen {
+				state.Symbols = append(state.Symbols, symbol)
+			}
+			kernels[symbol] = append(kernels[symbol], Item{Rule: item.Rule, Dot: item.Dot + 1})
+		}
+		for _, symbol := range state.Symbols {
+			state.Transitions[symbol] = addState(kernels[symbol])
+		}
+	}
+	return a
+}
+
+// closure returns kernel followed by the items of the rules
+// of every nonterminal that comes right after a dot
+func (a *Automaton) closure(kernel []Item) []Item {
+	items := append([]Item{}, kernel...)
+	expanded := map[string]bool{}
+	for idx := 0; idx < len(items); idx++ {
+		symbol, found := a.nextSymbol(items[idx])
+		if !found || a.grammar.IsTerminal(symbol) || expanded[symbol] {
+			continue
+		}
+		expanded[symbol] = true
+		for _, rule := range a.grammar.rulesOf(symbol) {
+			item := Item{Rule: rule, Dot: 0}
+			if !containsItem(items, item) {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// nextSymbol returns the symbol right after the
+// dot of item, and false when the dot is at the end
+func (a *Automaton) nextSymbol(item Item) (string, bool) {
+	right := a.grammar.rules[item.Rule].Right
+	if item.Dot >= len(right) {
+		return "", false
+	}
+	return right[item.Dot], true
+}
+
+// isComplete returns whether the dot of
+// item is at the end of its rule
+func (a *Automaton) isComplete(item Item) bool {
+	_, found := a.nextSymbol(item)
+	return !found
+}
+
+// States returns the states of the automaton
+func (a *Automaton) States() []*LR0State {
+	return a.states
+}
+
+// Grammar returns the grammar the automaton recognizes
+func (a *Automaton) Grammar() *Grammar {
+	return a.grammar
+}
+
+// ItemString returns item the way it is
+// written on books, like A -> ES . A
+func (a *Automaton) ItemString(item Item) string {
+	rule := a.grammar.rules[item.Rule]
+	symbols := append([]string{}, rule.Right[:item.Dot]...)
+	symbols = append(symbols, ".")
+	symbols = append(symbols, rule.Right[item.Dot:]...)
+	return fmt.Sprintf("%s -> %s", rule.Left, strings.Join(symbols, " "))
+}
+
+func containsItem(items []Item, item Item) bool {
+	for _, other := range items {
+		if other == item {
+			return true
+		}
+	}
+	return false
+}
+
+// kernelKey identifies a kernel regardless
+// of the order of its items
+func kernelKey(kernel []Item) string {
+	sorted := append([]Item{}, kernel...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Rule != sorted[j].Rule {
+			return sorted[i].Rule < sorted[j].Rule
+		}
+		return sorted[i].Dot < sorted[j].Dot
+	})
+	return fmt.Sprint(sorted)
+}
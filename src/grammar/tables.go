@@ -0,0 +1,297 @@
+package grammar
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrorUnknownMethod = fmt.Errorf("unknown table construction method, expected slr or lalr")
+)
+
+// Method is how the lookaheads of the reductions are chosen
+type Method string
+
+const (
+	// SLR reduces on the FOLLOW set of the rule
+	SLR Method = "slr"
+	// LALR reduces on the LALR(1) lookaheads of the item
+	LALR Method = "lalr"
+)
+
+// ParseMethod returns the Method called name
+func ParseMethod(name string) (Method, error) {
+	switch method := Method(name); method {
+	case SLR, LALR:
+		return method, nil
+	}
+	return "", ErrorUnknownMethod
+}
+
+type ActionKind int
+
+const (
+	Error ActionKind = iota
+	Shift
+	Reduce
+	Accept
+)
+
+// Action is a cell of the action table. Shifts go to the
+// state Operand, reductions use the rule numbered Operand and
+// errors have the category Operand, where 0 means none
+type Action struct {
+	Kind    ActionKind
+	Operand int
+}
+
+// String returns the action the way
+// the parser reads it from action.tsv
+func (a Action) String() string {
+	switch a.Kind {
+	case Shift:
+		return fmt.Sprintf("s%d", a.Operand)
+	case Reduce:
+		return fmt.Sprintf("r%d", a.Operand)
+	case Accept:
+		return "acc"
+	}
+	if a.Operand == 0 {
+		return ""
+	}
+	return fmt.Sprintf("e%d", a.Operand)
+}
+
+// Conflict is a cell of the action table where more than one
+// action is possible. The first of Actions is the one kept on
+// the table: shifts win over reductions, like yacc does, and
+// reductions of rules that come first win over the others
+type Conflict struct {
+	State    int
+	Terminal string
+	Actions  []Action
+}
+
+func (c Conflict) String() string {
+	actions := []string{}
+	for _, action := range c.Actions {
+		actions = append(actions, action.String())
+	}
+	return fmt.Sprintf("state %d reading %s: %s", c.State, c.Terminal, strings.Join(actions, ", "))
+}
+
+// ErrorCategories chooses the category of the errors of the
+// action table, the N of its eN cells. When every kernel item
+// of a state belongs to a nonterminal of ByNonterminal with the
+// same category, the whole row gets it, since the parser is in
+// the middle of that construction. Otherwise each cell gets the
+// category of its terminal on ByTerminal, or Default
+type ErrorCategories struct {
+	Default       int
+	ByNonterminal map[string]int
+	ByTerminal    map[string]int
+}
+
+// Tables are the LR tables of a grammar, the action table
+// with a column for each terminal and the goto table with a
+// column for each nonterminal. Empty goto cells hold -1
+type Tables struct {
+	Terminals    []string
+	Nonterminals []string
+	Action       [][]Action
+	Goto         [][]int
+	Conflicts    []Conflict
+}
+
+// GenerateTables builds the LR tables of the grammar made of
+// rules with method, the ones the parser is driven by
+func GenerateTables(rules []Rule, method Method, categories ErrorCategories) (*Tables, error) {
+	g, err := NewGrammar(rules)
+	if err != nil {
+		return nil, err
+	}
+	return NewAutomaton(g).Tables(method, categories)
+}
+
+// Tables builds the LR tables of the automaton with method
+func (a *Automaton) Tables(method Method, categories ErrorCategories) (*Tables, error) {
+	if _, err := ParseMethod(string(method)); err != nil {
+		return nil, err
+	}
+
+	g := a.grammar
+	terminalIndex := map[string]int{}
+	for idx, terminal := range g.terminals {
+		terminalIndex[terminal] = idx
+	}
+	nonterminalIndex := map[string]int{}
+	for idx, nonterminal := range g.nonterminals {
+		nonterminalIndex[nonterminal] = idx
+	}
+
+	reductions := a.reductions(method)
+	tables := &Tables{
+		Terminals:    g.terminals,
+		Nonterminals: g.nonterminals,
+	}
+
+	for idx, state := range a.states {
+		candidates := make([][]Action, len(g.terminals))
+		gotoRow := make([]int, len(g.nonterminals))
+		for column := range gotoRow {
+			gotoRow[column] = -1
+		}
+
+		for _, symbol := range state.Symbols {
+			to := state.Transitions[symbol]
+			if g.IsTerminal(symbol) {
+				column := terminalIndex[symbol]
+				candidates[column] = append(candidates[column], Action{Kind: Shift, Operand: to})
+			} else {
+				gotoRow[nonterminalIndex[symbol]] = to
+			}
+		}
+
+		for _, item := range state.Items {
+			if !a.isComplete(item) {
+				continue
+			}
+			for _, terminal := range g.sortTerminals(reductions[idx][item]) {
+				column := terminalIndex[terminal]
+				if item.Rule == 0 {
+					candidates[column] = append(candidates[column], Action{Kind: Accept})
+					continue
+				}
+				candidates[column] = append(candidates[column], Action{Kind: Reduce, Operand: g.rules[item.Rule].Number})
+			}
+		}
+
+		actionRow := make([]Action, len(g.terminals))
+		for column, actions := range candidates {
+			if len(actions) == 0 {
+				actionRow[column] = Action{Kind: Error, Operand: categories.category(a, state, g.terminals[column])}
+				continue
+			}
+			sortByPrecedence(actions)
+			actionRow[column] = actions[0]
+			if len(actions) > 1 {
+				tables.Conflicts = append(tables.Conflicts, Conflict{
+					State:    idx,
+					Terminal: g.terminals[column],
+					Actions:  actions,
+				})
+			}
+		}
+
+		tables.Action = append(tables.Action, actionRow)
+		tables.Goto = append(tables.Goto, gotoRow)
+	}
+	return tables, nil
+}
+
+// reductions returns, for every state, the
+// lookaheads of each of its complete items
+func (a *Automaton) reductions(method Method) []lookaheadSets {
+	result := make([]lookaheadSets, len(a.states))
+
+	if method == SLR {
+		for idx, state := range a.states {
+			result[idx] = lookaheadSets{}
+			for _, item := range state.Items {
+				if a.isComplete(item) {
+					result[idx][item] = a.grammar.follow[a.grammar.rules[item.Rule].Left]
+				}
+			}
+		}
+		return result
+	}
+
+	kernelLookaheads := a.lalrLookaheads()
+	for idx, state := range a.states {
+		_, sets := a.closureWithLookaheads(state.Kernel, kernelLookaheads[idx])
+		result[idx] = lookaheadSets{}
+		for item, set := range sets {
+			if a.isComplete(item) {
+				result[idx][item] = set
+			}
+		}
+	}
+	return result
+}
+
+// sortByPrecedence puts first the action
+// kept on a cell with a conflict
+func sortByPrecedence(actions []Action) {
+	rank := map[ActionKind]int{Accept: 0, Shift: 1, Reduce: 2}
+	sort.SliceStable(actions, func(i, j int) bool {
+		if actions[i].Kind != actions[j].Kind {
+			return rank[actions[i].Kind] < rank[actions[j].Kind]
+		}
+		return actions[i].Operand < actions[j].Operand
+	})
+}
+
+// category returns the category of the error of
+// reading terminal on state
+func (c ErrorCategories) category(a *Automaton, state *LR0State, terminal string) int {
+	rowCategory := 0
+	for _, item := range state.Kernel {
+		category, found := c.ByNonterminal[a.grammar.rules[item.Rule].Left]
+		if !found || rowCategory != 0 && category != rowCategory {
+			rowCategory = 0
+			break
+		}
+		rowCategory = category
+	}
+	if rowCategory != 0 {
+		return rowCategory
+	}
+
+	if category, found := c.ByTerminal[terminal]; found {
+		return category
+	}
+	return c.Default
+}
+
+// WriteAction writes the action table as tab separated
+// values, the format read by the parser
+func (t *Tables) WriteAction(writer io.Writer) error {
+	records := [][]string{append([]string{"estado"}, t.Terminals...)}
+	for state, row := range t.Action {
+		record := []string{strconv.Itoa(state)}
+		for _, action := range row {
+			record = append(record, action.String())
+		}
+		records = append(records, record)
+	}
+	return writeTable(writer, records)
+}
+
+// WriteGoto writes the goto table as tab separated
+// values, the format read by the parser
+func (t *Tables) WriteGoto(writer io.Writer) error {
+	records := [][]string{append([]string{"estado"}, t.Nonterminals...)}
+	for state, row := range t.Goto {
+		record := []string{strconv.Itoa(state)}
+		for _, to := range row {
+			if to < 0 {
+				record = append(record, "")
+			} else {
+				record = append(record, strconv.Itoa(to))
+			}
+		}
+		records = append(records, record)
+	}
+	return writeTable(writer, records)
+}
+
+func writeTable(writer io.Writer, records [][]string) error {
+	tsv := csv.NewWriter(writer)
+	tsv.Comma = '\t'
+	tsv.UseCRLF = true
+	return tsv.WriteAll(records)
+}
@@ -0,0 +1,262 @@
+package grammar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recognize runs the LR parser driven by tables over input,
+// returning whether it was accepted and the rules reduced
+func recognize(tables *Tables, rules []Rule, input string) (bool, []int) {
+	terminalIndex := map[string]int{}
+	for idx, terminal := range tables.Terminals {
+		terminalIndex[terminal] = idx
+	}
+	nonterminalIndex := map[string]int{}
+	for idx, nonterminal := range tables.Nonterminals {
+		nonterminalIndex[nonterminal] = idx
+	}
+	ruleOf := map[int]Rule{}
+	for _, rule := range rules {
+		ruleOf[rule.Number] = rule
+	}
+
+	tokens := append(strings.Fields(input), EndOfInput)
+	states := []int{0}
+	reduced := []int{}
+	for {
+		column, found := terminalIndex[tokens[0]]
+		if !found {
+			return false, reduced
+		}
+
+		action := tables.Action[states[len(states)-1]][column]
+		switch action.Kind {
+		case Shift:
+			states = append(states, action.Operand)
+			tokens = tokens[1:]
+		case Reduce:
+			rule := ruleOf[action.Operand]
+			states = states[:len(states)-len(rule.Right)]
+			states = append(states, tables.Goto[states[len(states)-1]][nonterminalIndex[rule.Left]])
+			reduced = append(reduced, rule.Number)
+		case Accept:
+			return true, reduced
+		default:
+			return false, reduced
+		}
+	}
+}
+
+func TestNewAutomaton(t *testing.T) {
+	r := require.New(t)
+
+	g, err := NewGrammar(expressionRules)
+	r.NoError(err)
+
+	automaton := NewAutomaton(g)
+	r.Len(automaton.States(), 12)
+
+	initial := automaton.States()[0]
+	r.Equal([]Item{{Rule: 0, Dot: 0}}, initial.Kernel)
+	r.Equal([]string{"E", "T", "F", "(", "id"}, initial.Symbols)
+	r.Equal("E' -> . E", automaton.ItemString(initial.Items[0]))
+	r.Equal("E -> E . + T", automaton.ItemString(Item{Rule: 1, Dot: 1}))
+}
+
+func TestGenerateTables(t *testing.T) {
+	testCases := []struct {
+		name              string
+		rules             []Rule
+		method            Method
+		expectedConflicts int
+		accepted          []string
+		rejected          []string
+	}{
+		{
+			name:     "SLR expressions",
+			rules:    expressionRules,
+			method:   SLR,
+			accepted: []string{"id", "id + id * id", "( id + id ) * id"},
+			rejected: []string{"", "id +", "( id", "id id"},
+		},
+		{
+			name:     "LALR expressions",
+			rules:    expressionRules,
+			method:   LALR,
+			accepted: []string{"id", "id + id * id", "( id + id ) * id"},
+			rejected: []string{"", "id +", "( id", "id id"},
+		},
+		{
+			name:              "Grammar that is not SLR",
+			rules:             assignmentRules,
+			method:            SLR,
+			expectedConflicts: 1,
+		},
+		{
+			name:     "Grammar that is LALR",
+			rules:    assignmentRules,
+			method:   LALR,
+			accepted: []string{"id", "id = id", "* id = * * id", "* id"},
+			rejected: []string{"id = = id", "=", "id ="},
+		},
+		{
+			name:     "Empty rules",
+			rules:    emptyRules,
+			method:   LALR,
+			accepted: []string{"b", "a b", "a a a b"},
+			rejected: []string{"", "a", "b a"},
+		},
+		{
+			name:              "Ambiguous grammar",
+			rules:             rulesOf("E' -> E", "E -> E + E", "E -> id"),
+			method:            LALR,
+			expectedConflicts: 1,
+			accepted:          []string{"id + id + id"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			tables, err := GenerateTables(tc.rules, tc.method, ErrorCategories{})
+			r.NoError(err)
+			r.Len(tables.Conflicts, tc.expectedConflicts)
+
+			for _, input := range tc.accepted {
+				accepted, _ := recognize(tables, tc.rules, input)
+				r.True(accepted, input)
+			}
+			for _, input := range tc.rejected {
+				accepted, _ := recognize(tables, tc.rules, input)
+				r.False(accepted, input)
+			}
+		})
+	}
+}
+
+func TestGenerateTablesReductions(t *testing.T) {
+	r := require.New(t)
+
+	tables, err := GenerateTables(expressionRules, LALR, ErrorCategories{})
+	r.NoError(err)
+
+	accepted, reduced := recognize(tables, expressionRules, "id + id * id")
+	r.True(accepted)
+	r.Equal([]int{6, 4, 2, 6, 4, 6, 3, 1}, reduced)
+}
+
+func TestGenerateTablesWithErrorProductions(t *testing.T) {
+	r := require.New(t)
+
+	rules := rulesOf(
+		"S' -> L",
+		"L -> L C",
+		"L -> C",
+		"C -> a ;",
+		"C -> error ;",
+	)
+	tables, err := GenerateTables(rules, LALR, ErrorCategories{})
+	r.NoError(err)
+	r.Empty(tables.Conflicts)
+	r.Equal([]string{"a", ";", ErrorSymbol, EndOfInput}, tables.Terminals)
+
+	// The parser shifts the error symbol
+	// where it starts to recover
+	accepted, reduced := recognize(tables, rules, "a ; error ; a ;")
+	r.True(accepted)
+	r.Equal([]int{3, 2, 4, 1, 3, 1}, reduced)
+}
+
+func TestConflicts(t *testing.T) {
+	r := require.New(t)
+
+	tables, err := GenerateTables(rulesOf("E' -> E", "E -> E + E", "E -> id"), LALR, ErrorCategories{})
+	r.NoError(err)
+	r.Len(tables.Conflicts, 1)
+
+	conflict := tables.Conflicts[0]
+	r.Equal("+", conflict.Terminal)
+	r.Equal(Shift, conflict.Actions[0].Kind)
+	r.Equal(Action{Kind: Reduce, Operand: 1}, conflict.Actions[1])
+	r.Equal(conflict.Actions[0], tables.Action[conflict.State][0])
+	r.Contains(conflict.String(), "reading +: s")
+}
+
+func TestErrorCategories(t *testing.T) {
+	r := require.New(t)
+
+	categories := ErrorCategories{
+		Default:       1,
+		ByNonterminal: map[string]int{"F": 9},
+		ByTerminal:    map[string]int{"+": 7, "*": 7},
+	}
+	tables, err := GenerateTables(expressionRules, LALR, categories)
+	r.NoError(err)
+
+	g, err := NewGrammar(expressionRules)
+	r.NoError(err)
+	automaton := NewAutomaton(g)
+
+	// State 0 starts the expression, so each
+	// error gets the category of its terminal
+	r.Equal(Action{Kind: Error, Operand: 7}, tables.Action[0][0])
+	r.Equal(Action{Kind: Error, Operand: 1}, tables.Action[0][3])
+
+	// After ( the parser is inside F
+	open := automaton.States()[0].Transitions["("]
+	for column, action := range tables.Action[open] {
+		if action.Kind == Error {
+			r.Equal(9, action.Operand, tables.Terminals[column])
+		}
+	}
+}
+
+func TestParseMethod(t *testing.T) {
+	r := require.New(t)
+
+	method, err := ParseMethod("slr")
+	r.NoError(err)
+	r.Equal(SLR, method)
+
+	method, err = ParseMethod("lalr")
+	r.NoError(err)
+	r.Equal(LALR, method)
+
+	_, err = ParseMethod("lr1")
+	r.ErrorIs(err, ErrorUnknownMethod)
+
+	_, err = GenerateTables(expressionRules, Method("lr1"), ErrorCategories{})
+	r.ErrorIs(err, ErrorUnknownMethod)
+}
+
+func TestWriteTables(t *testing.T) {
+	r := require.New(t)
+
+	tables, err := GenerateTables(rulesOf("S' -> S", "S -> ( S )", "S -> x"), LALR, ErrorCategories{Default: 1})
+	r.NoError(err)
+
+	action := &bytes.Buffer{}
+	r.NoError(tables.WriteAction(action))
+	r.Equal("estado\t(\t)\tx\t$\r\n"+
+		"0\ts2\te1\ts3\te1\r\n"+
+		"1\te1\te1\te1\tacc\r\n"+
+		"2\ts2\te1\ts3\te1\r\n"+
+		"3\te1\tr2\te1\tr2\r\n"+
+		"4\te1\ts5\te1\te1\r\n"+
+		"5\te1\tr1\te1\tr1\r\n", action.String())
+
+	gotoTable := &bytes.Buffer{}
+	r.NoError(tables.WriteGoto(gotoTable))
+	r.Equal("estado\tS'\tS\r\n"+
+		"0\t\t1\r\n"+
+		"1\t\t\r\n"+
+		"2\t\t4\r\n"+
+		"3\t\t\r\n"+
+		"4\t\t\r\n"+
+		"5\t\t\r\n", gotoTable.String())
+}
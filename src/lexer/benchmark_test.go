@@ -0,0 +1,62 @@
+package lexer
+
+import (
+	"fmt"
+	errorhandling "mgol-go/src/error_handling"
+	"strings"
+	"testing"
+)
+
+const benchmarkProgramSize = 4 << 20
+
+// generateProgram returns a valid mgol program
+// with at least size bytes
+func generateProgram(size int) string {
+	var program strings.Builder
+	program.WriteString("inicio\nvarinicio\n")
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&program, "inteiro contador_%d;\nreal média_%d;\nliteral nome_%d;\n", i, i, i)
+	}
+	program.WriteString("varfim;\n")
+
+	for i := 0; program.Len() < size; i++ {
+		v := i % 100
+		fmt.Fprintf(&program, "{ bloco %d: calcula a média }\n", i)
+		fmt.Fprintf(&program, "leia contador_%d;\n", v)
+		fmt.Fprintf(&program, "contador_%d <- contador_%d + %d;\n", v, v, i)
+		fmt.Fprintf(&program, "média_%d <- média_%d * 1.5e-3;\n", v, v)
+		fmt.Fprintf(&program, "se(contador_%d >= 10)\nentao\n\tescreva \"Informe a média: \";\nfimse\n", v)
+		fmt.Fprintf(&program, "repita(contador_%d <> 0)\n\tcontador_%d <- contador_%d - 1;\nfimrepita\n", v, v, v)
+	}
+	program.WriteString("fim\n")
+	return program.String()
+}
+
+func BenchmarkScan(b *testing.B) {
+	program := generateProgram(benchmarkProgramSize)
+	symbolTable := GetSymbolTableInstance()
+	FillSymbolTable(symbolTable)
+	defer symbolTable.Cleanup()
+
+	b.SetBytes(int64(len(program)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		scanner := NewScanner(strings.NewReader(program), symbolTable, errorhandling.NewCollector())
+		for !scanner.Scan().IsClass(EOF) {
+		}
+	}
+}
+
+func BenchmarkCompileSpec(b *testing.B) {
+	specs, err := LoadLexerSpec(strings.NewReader(string(defaultSpecFile)))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		if _, err := CompileSpec(specs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -2,34 +2,88 @@ package lexer
 
 import (
 	"fmt"
+	"sort"
 )
 
 var (
 	ErrorInvalidInitialState    = fmt.Errorf("provided an invalid initial state")
 	ErrorInvalidFinalStateSet   = fmt.Errorf("provided an invalid final state set")
+	ErrorInvalidTransition      = fmt.Errorf("provided a transition between unknown states")
 	ErrorNotFinalState          = fmt.Errorf("provided state does not represent a final state")
 	ErrorTransitionDoesNotExist = fmt.Errorf("transition does not exist")
 )
 
 type State int
-type Symbol byte
+type Symbol rune
+
+// noState marks the absence of a transition on the table
+const noState State = -1
+
+// directClasses is how many symbols, starting at 0, have
+// their class stored on an array instead of searched. It
+// covers ASCII and the accented letters of latin scripts
+const directClasses = 0x800
 
 type Transition struct {
 	from    State
 	to      State
 	reading []Symbol
+	// ranges holds symbols that can't be listed
+	// one by one on reading, like unicode letters
+	ranges runeSet
+}
+
+// symbols returns every symbol consumed by t
+func (t Transition) symbols() runeSet {
+	set := runeSet{}
+	for _, symbol := range t.reading {
+		set = append(set, runeRange{lo: rune(symbol), hi: rune(symbol)})
+	}
+	return newRuneSet(append(set, t.ranges...)...)
+}
+
+// symbolClasses maps every symbol to its equivalence
+// class. Two symbols are in the same class when every
+// state moves to the same place reading either of them.
+// Class 0 holds the symbols that have no transition
+type symbolClasses struct {
+	direct [directClasses]uint16
+	ranges runeSet
+	class  []uint16
 }
 
+func (c *symbolClasses) get(char Symbol) int {
+	if char >= 0 && char < directClasses {
+		return int(c.direct[char])
+	}
+
+	idx := sort.Search(len(c.ranges), func(i int) bool {
+		return c.ranges[i].hi >= rune(char)
+	})
+	if idx < len(c.ranges) && c.ranges[idx].lo <= rune(char) {
+		return int(c.class[idx])
+	}
+	return 0
+}
+
+// Dft is a deterministic finite automaton stored as a dense
+// table, with a row for each state and a column for each
+// symbol class, so every step costs a single lookup
 type Dft struct {
-	alphabet      []Symbol
-	states        []State
-	initialState  State
-	finalStates   []State
-	transitionMap map[State][]Transition
-	currentState  State
+	states       []State
+	initialState State
+	finalStates  []State
+	isFinal      []bool
+	classes      *symbolClasses
+	numClasses   int
+	table        []State
+	currentState State
 }
 
-func NewDft(alphabet []Symbol, states []State, initialState State, finalStates []State, transitionMap map[State][]Transition) (*Dft, error) {
+// NewDft builds a dft whose states are numbered from 0 to
+// len(states)-1. The transitions are compiled into the
+// dense table, grouping the symbols in equivalence classes
+func NewDft(states []State, initialState State, finalStates []State, transitionMap map[State][]Transition) (*Dft, error) {
 	if !ContainsState(states, initialState) {
 		return &Dft{}, ErrorInvalidInitialState
 	}
@@ -38,40 +92,147 @@ func NewDft(alphabet []Symbol, states []State, initialState State, finalStates [
 		return &Dft{}, ErrorInvalidFinalStateSet
 	}
 
+	isFinal := make([]bool, len(states))
+	for _, state := range finalStates {
+		if state < 0 || int(state) >= len(states) {
+			return &Dft{}, ErrorInvalidFinalStateSet
+		}
+		isFinal[state] = true
+	}
+
+	// Split the symbols in the smallest ranges that
+	// each transition either covers or ignores
+	symbolsOf := map[State][]runeSet{}
+	allSets := []runeSet{}
+	for state, transitions := range transitionMap {
+		for _, transition := range transitions {
+			if !ContainsState(states, state) || !ContainsState(states, transition.to) {
+				return &Dft{}, ErrorInvalidTransition
+			}
+			set := transition.symbols()
+			symbolsOf[state] = append(symbolsOf[state], set)
+			allSets = append(allSets, set)
+		}
+	}
+	atoms := partition(allSets)
+
+	// Fill the column of each range, then merge the
+	// ranges whose columns are equal into classes
+	columns := make([][]State, len(atoms))
+	for idx := range columns {
+		columns[idx] = make([]State, len(states))
+		for state := range columns[idx] {
+			columns[idx][state] = noState
+		}
+	}
+	for state, transitions := range transitionMap {
+		for idx, transition := range transitions {
+			for _, atom := range atomsOf(atoms, symbolsOf[state][idx]) {
+				columns[atom][state] = transition.to
+			}
+		}
+	}
+
+	classes := &symbolClasses{}
+	classColumns := [][]State{make([]State, len(states))}
+	for state := range classColumns[0] {
+		classColumns[0][state] = noState
+	}
+	classIndexes := map[string]uint16{fmt.Sprint(classColumns[0]): 0}
+
+	for idx, column := range columns {
+		key := fmt.Sprint(column)
+		class, found := classIndexes[key]
+		if !found {
+			class = uint16(len(classColumns))
+			classIndexes[key] = class
+			classColumns = append(classColumns, column)
+		}
+
+		atom := atoms[idx]
+		for char := atom.lo; char <= atom.hi && char < directClasses; char++ {
+			classes.direct[char] = class
+		}
+		if atom.hi >= directClasses {
+			if atom.lo < directClasses {
+				atom.lo = directClasses
+			}
+			classes.ranges = append(classes.ranges, atom)
+			classes.class = append(classes.class, class)
+		}
+	}
+
+	numClasses := len(classColumns)
+	table := make([]State, len(states)*numClasses)
+	for class, column := range classColumns {
+		for state, to := range column {
+			table[state*numClasses+class] = to
+		}
+	}
+
 	return &Dft{
-		alphabet:      alphabet,
-		states:        states,
-		initialState:  initialState,
-		finalStates:   finalStates,
-		transitionMap: transitionMap,
-		currentState:  initialState,
+		states:       states,
+		initialState: initialState,
+		finalStates:  finalStates,
+		isFinal:      isFinal,
+		classes:      classes,
+		numClasses:   numClasses,
+		table:        table,
+		currentState: initialState,
 	}, nil
 }
 
-// Checks the existence of a certain symbol
-// inside a reading slice in a Transition
-func (d *Dft) transitionExists(char Symbol) bool {
-	possibleTransitions := d.transitionMap[d.currentState]
-	for _, transition := range possibleTransitions {
-		for _, symbol := range transition.reading {
-			if char == symbol {
-				return true
-			}
+// partition splits the symbols covered by sets into the
+// smallest ranges that every set either fully contains
+// or doesn't touch at all
+func partition(sets []runeSet) runeSet {
+	boundaries := map[rune]bool{}
+	for _, set := range sets {
+		for _, r := range set {
+			boundaries[r.lo] = true
+			boundaries[r.hi+1] = true
 		}
 	}
-	return false
+
+	points := make([]rune, 0, len(boundaries))
+	for point := range boundaries {
+		points = append(points, point)
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i] < points[j]
+	})
+
+	atoms := runeSet{}
+	for i := 0; i+1 < len(points); i++ {
+		atoms = append(atoms, runeRange{lo: points[i], hi: points[i+1] - 1})
+	}
+	return atoms
 }
 
-func (d *Dft) getNextTransition(char Symbol) Transition {
-	possibleTransitions := d.transitionMap[d.currentState]
-	for _, transition := range possibleTransitions {
-		for _, symbol := range transition.reading {
-			if char == symbol {
-				return transition
-			}
+// atomsOf returns the indexes of the atoms covered by set
+func atomsOf(atoms runeSet, set runeSet) []int {
+	indexes := []int{}
+	for _, r := range set {
+		first := sort.Search(len(atoms), func(i int) bool {
+			return atoms[i].lo >= r.lo
+		})
+		for i := first; i < len(atoms) && atoms[i].hi <= r.hi; i++ {
+			indexes = append(indexes, i)
 		}
 	}
-	return Transition{}
+	return indexes
+}
+
+// step returns where state goes reading char,
+// or noState if there is no such transition
+func (d *Dft) step(state State, char Symbol) State {
+	return d.table[int(state)*d.numClasses+d.classes.get(char)]
+}
+
+// Checks the existence of a transition from
+// the current state reading char
+func (d *Dft) transitionExists(char Symbol) bool {
+	return d.step(d.currentState, char) != noState
 }
 
 // Next updates and returns the next state when consuming
@@ -79,13 +240,12 @@ func (d *Dft) getNextTransition(char Symbol) Transition {
 // possible to be made, Next returns the
 // inital state and ErrorTransitionDoesNotExist
 func (d *Dft) Next(char Symbol) (State, error) {
-	if !d.transitionExists(char) {
+	next := d.step(d.currentState, char)
+	if next == noState {
 		return d.initialState, ErrorTransitionDoesNotExist
 	}
 
-	nextTransition := d.getNextTransition(char)
-	d.currentState = nextTransition.to
-
+	d.currentState = next
 	return d.currentState, nil
 }
 
@@ -96,9 +256,21 @@ func (d *Dft) Reset() {
 
 // IsFinalState returns whether we stopped on a final state or not
 func (d *Dft) IsFinalState() bool {
-	return ContainsState(d.finalStates, d.currentState)
+	return d.isFinal[d.currentState]
 }
 
 func (d *Dft) GetCurrentState() State {
 	return d.currentState
 }
+
+// NumStates returns how many states the dft has
+func (d *Dft) NumStates() int {
+	return len(d.states)
+}
+
+// NumSymbolClasses returns how many equivalence classes the
+// symbols were split into, counting the one of the symbols
+// that have no transition
+func (d *Dft) NumSymbolClasses() int {
+	return d.numClasses
+}
@@ -1 +1,137 @@
 package lexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newRedundantDft returns a dft for a(b|c)d* where the
+// states after b and after c are equivalent
+func newRedundantDft(t *testing.T) *Dft {
+	dft, err := NewDft(
+		[]State{0, 1, 2, 3, 4},
+		0,
+		[]State{2, 3, 4},
+		map[State][]Transition{
+			0: {{from: 0, to: 1, reading: []Symbol{'a'}}},
+			1: {
+				{from: 1, to: 2, reading: []Symbol{'b'}},
+				{from: 1, to: 3, reading: []Symbol{'c'}},
+			},
+			2: {{from: 2, to: 4, reading: []Symbol{'d'}}},
+			3: {{from: 3, to: 4, reading: []Symbol{'d'}}},
+			4: {{from: 4, to: 4, reading: []Symbol{'d'}}},
+		},
+	)
+	require.NoError(t, err)
+	return dft
+}
+
+func accepts(dft *Dft, text string) bool {
+	dft.Reset()
+	for _, char := range text {
+		if _, err := dft.Next(Symbol(char)); err != nil {
+			return false
+		}
+	}
+	return dft.IsFinalState()
+}
+
+func TestNewDft(t *testing.T) {
+	r := require.New(t)
+
+	dft := newRedundantDft(t)
+	r.Equal(5, dft.NumStates())
+	// One class for each letter plus the one without transitions
+	r.Equal(5, dft.NumSymbolClasses())
+
+	_, err := NewDft([]State{0}, 1, nil, nil)
+	r.ErrorIs(err, ErrorInvalidInitialState)
+
+	_, err = NewDft([]State{0}, 0, []State{3}, nil)
+	r.ErrorIs(err, ErrorInvalidFinalStateSet)
+
+	_, err = NewDft([]State{0}, 0, nil, map[State][]Transition{0: {{from: 0, to: 7, reading: []Symbol{'a'}}}})
+	r.ErrorIs(err, ErrorInvalidTransition)
+}
+
+func TestMinimize(t *testing.T) {
+	testCases := []struct {
+		name            string
+		labels          map[State]string
+		expectedStates  int
+		expectedClasses int
+	}{
+		{
+			name:            "Equivalent states are merged",
+			labels:          map[State]string{2: "x", 3: "x", 4: "x"},
+			expectedStates:  3,
+			expectedClasses: 4,
+		},
+		{
+			name:            "Final states with different labels are kept apart",
+			labels:          map[State]string{2: "x", 3: "y", 4: "x"},
+			expectedStates:  4,
+			expectedClasses: 5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			dft := newRedundantDft(t)
+			minimal, mapping := dft.Minimize(tc.labels)
+			r.Equal(tc.expectedStates, minimal.NumStates())
+			r.Equal(tc.expectedClasses, minimal.NumSymbolClasses())
+			r.Equal(State(0), mapping[0])
+
+			for _, text := range []string{"ab", "ac", "abd", "acddd"} {
+				r.True(accepts(minimal, text), text)
+			}
+			for _, text := range []string{"", "a", "ad", "abc", "bd"} {
+				r.False(accepts(minimal, text), text)
+			}
+		})
+	}
+}
+
+func TestMinimizeDropsDeadStates(t *testing.T) {
+	r := require.New(t)
+
+	dft, err := NewDft(
+		[]State{0, 1, 2},
+		0,
+		[]State{1},
+		map[State][]Transition{
+			0: {
+				{from: 0, to: 1, reading: []Symbol{'a'}},
+				{from: 0, to: 2, reading: []Symbol{'b'}},
+			},
+			2: {{from: 2, to: 2, reading: []Symbol{'b'}}},
+		},
+	)
+	r.NoError(err)
+
+	minimal, mapping := dft.Minimize(nil)
+	r.Equal(2, minimal.NumStates())
+	r.NotContains(mapping, State(2))
+
+	minimal.Reset()
+	_, err = minimal.Next('b')
+	r.ErrorIs(err, ErrorTransitionDoesNotExist)
+}
+
+func TestDefaultSpecIsMinimal(t *testing.T) {
+	r := require.New(t)
+
+	dft := GetDefaultSpec().GetDft()
+	labels := map[State]string{}
+	for state, spec := range GetDefaultSpec().accepts {
+		labels[state] = string(spec.Class) + string(spec.DataType)
+	}
+	minimal, _ := dft.Minimize(labels)
+	r.Equal(dft.NumStates(), minimal.NumStates())
+	r.Less(dft.NumSymbolClasses(), 32)
+}
@@ -0,0 +1,205 @@
+package lexer
+
+import "fmt"
+
+// Minimize returns the dft with the fewest states that
+// recognizes the same language as d, built with the Hopcroft
+// algorithm. Final states are only merged when they have the
+// same label, so they keep telling which token they recognize.
+// States that can't reach a final state are dropped. It also
+// returns the state of the new dft each state of d became
+func (d *Dft) Minimize(labels map[State]string) (*Dft, map[State]State) {
+	// An extra sink state makes the transition function total
+	numStates := len(d.states) + 1
+	sink := State(numStates - 1)
+	delta := func(state State, class int) State {
+		if state == sink {
+			return sink
+		}
+		to := d.table[int(state)*d.numClasses+class]
+		if to == noState {
+			return sink
+		}
+		return to
+	}
+
+	inverse := make([][][]State, d.numClasses)
+	for class := range inverse {
+		inverse[class] = make([][]State, numStates)
+		for state := State(0); state < State(numStates); state++ {
+			to := delta(state, class)
+			inverse[class][to] = append(inverse[class][to], state)
+		}
+	}
+
+	// The initial partition groups the states by label
+	block := make([]int, numStates)
+	blocks := [][]State{}
+	blockOfLabel := map[string]int{}
+	for state := State(0); state < State(numStates); state++ {
+		label := "non final"
+		if state != sink && d.isFinal[state] {
+			label = fmt.Sprintf("final %s", labels[state])
+		}
+		idx, found := blockOfLabel[label]
+		if !found {
+			idx = len(blocks)
+			blockOfLabel[label] = idx
+			blocks = append(blocks, []State{})
+		}
+		block[state] = idx
+		blocks[idx] = append(blocks[idx], state)
+	}
+
+	worklist := []int{}
+	inWorklist := make([]bool, len(blocks))
+	for idx := range blocks {
+		worklist = append(worklist, idx)
+		inWorklist[idx] = true
+	}
+
+	for len(worklist) > 0 {
+		splitter := worklist[len(worklist)-1]
+		worklist = worklist[:len(worklist)-1]
+		inWorklist[splitter] = false
+		members := append([]State{}, blocks[splitter]...)
+
+		for class := 0; class < d.numClasses; class++ {
+			// Find the states that move into the splitter
+			// reading class, grouped by their block
+			predecessors := map[int][]State{}
+			for _, to := range members {
+				for _, from := range inverse[class][to] {
+					predecessors[block[from]] = append(predecessors[block[from]], from)
+				}
+			}
+
+			for idx, moving := range predecessors {
+				if len(moving) == len(blocks[idx]) {
+					continue
+				}
+
+				isMoving := map[State]bool{}
+				for _, state := range moving {
+					isMoving[state] = true
+				}
+				staying := []State{}
+				for _, state := range blocks[idx] {
+					if !isMoving[state] {
+						staying = append(staying, state)
+					}
+				}
+
+				newIdx := len(blocks)
+				blocks[idx] = staying
+				blocks = append(blocks, moving)
+				inWorklist = append(inWorklist, false)
+				for _, state := range moving {
+					block[state] = newIdx
+				}
+
+				if inWorklist[idx] || len(moving) <= len(staying) {
+					worklist = append(worklist, newIdx)
+					inWorklist[newIdx] = true
+				} else {
+					worklist = append(worklist, idx)
+					inWorklist[idx] = true
+				}
+			}
+		}
+	}
+
+	// The block of the sink holds the dead states
+	dead := block[sink]
+	if block[d.initialState] == dead {
+		dead = -1
+	}
+
+	newState := map[int]State{}
+	representatives := []State{}
+	mapping := map[State]State{}
+	for state := State(0); state < sink; state++ {
+		idx := block[state]
+		if idx == dead {
+			continue
+		}
+		if _, found := newState[idx]; !found {
+			newState[idx] = State(len(representatives))
+			representatives = append(representatives, state)
+		}
+		mapping[state] = newState[idx]
+	}
+
+	states := make([]State, len(representatives))
+	finalStates := []State{}
+	isFinal := make([]bool, len(representatives))
+	columns := make([][]State, d.numClasses)
+	for class := range columns {
+		columns[class] = make([]State, len(representatives))
+	}
+	for idx, representative := range representatives {
+		states[idx] = State(idx)
+		if d.isFinal[representative] {
+			finalStates = append(finalStates, State(idx))
+			isFinal[idx] = true
+		}
+		for class := range columns {
+			to := delta(representative, class)
+			if to == sink || block[to] == dead {
+				columns[class][idx] = noState
+				continue
+			}
+			columns[class][idx] = newState[block[to]]
+		}
+	}
+
+	classes, classColumns := mergeClasses(d.classes, columns)
+	numClasses := len(classColumns)
+	table := make([]State, len(states)*numClasses)
+	for class, column := range classColumns {
+		for state, to := range column {
+			table[state*numClasses+class] = to
+		}
+	}
+
+	return &Dft{
+		states:       states,
+		initialState: mapping[d.initialState],
+		finalStates:  finalStates,
+		isFinal:      isFinal,
+		classes:      classes,
+		numClasses:   numClasses,
+		table:        table,
+		currentState: mapping[d.initialState],
+	}, mapping
+}
+
+// mergeClasses joins the symbol classes whose columns
+// became equal, keeping the class 0 as the first one
+func mergeClasses(classes *symbolClasses, columns [][]State) (*symbolClasses, [][]State) {
+	merged := [][]State{}
+	renumber := make([]uint16, len(columns))
+	indexes := map[string]uint16{}
+	for class, column := range columns {
+		key := fmt.Sprint(column)
+		idx, found := indexes[key]
+		if !found {
+			idx = uint16(len(merged))
+			indexes[key] = idx
+			merged = append(merged, column)
+		}
+		renumber[class] = idx
+	}
+
+	result := &symbolClasses{
+		ranges: classes.ranges,
+		class:  make([]uint16, len(classes.class)),
+	}
+	for char, class := range classes.direct {
+		result.direct[char] = renumber[class]
+	}
+	for idx, class := range classes.class {
+		result.class[idx] = renumber[class]
+	}
+	return result, merged
+}
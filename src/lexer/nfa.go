@@ -0,0 +1,228 @@
+package lexer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const noAccept = -1
+
+// nfaState is a state of a nondeterministic automaton. It
+// moves to next consuming any character of set, if set is
+// not empty, and to any state of epsilon without consuming
+type nfaState struct {
+	set     runeSet
+	next    int
+	epsilon []int
+	accept  int
+}
+
+// nfaFragment is a piece of automaton built by the Thompson
+// construction, with a single entry and a single exit
+type nfaFragment struct {
+	start int
+	end   int
+}
+
+type nfa struct {
+	states []nfaState
+	start  int
+}
+
+func (n *nfa) newState() int {
+	n.states = append(n.states, nfaState{accept: noAccept})
+	return len(n.states) - 1
+}
+
+func (n *nfa) addEpsilon(from, to int) {
+	n.states[from].epsilon = append(n.states[from].epsilon, to)
+}
+
+// thompson adds to n the fragment that recognizes node
+func (n *nfa) thompson(node *regexNode) nfaFragment {
+	switch node.kind {
+	case regexSet:
+		start, end := n.newState(), n.newState()
+		n.states[start].set = node.set
+		n.states[start].next = end
+		return nfaFragment{start: start, end: end}
+
+	case regexConcat:
+		fragment := n.thompson(node.children[0])
+		for _, child := range node.children[1:] {
+			next := n.thompson(child)
+			n.addEpsilon(fragment.end, next.start)
+			fragment.end = next.end
+		}
+		return fragment
+
+	case regexAlternation:
+		start, end := n.newState(), n.newState()
+		for _, child := range node.children {
+			alternative := n.thompson(child)
+			n.addEpsilon(start, alternative.start)
+			n.addEpsilon(alternative.end, end)
+		}
+		return nfaFragment{start: start, end: end}
+
+	case regexStar:
+		start, end := n.newState(), n.newState()
+		body := n.thompson(node.children[0])
+		n.addEpsilon(start, body.start)
+		n.addEpsilon(start, end)
+		n.addEpsilon(body.end, body.start)
+		n.addEpsilon(body.end, end)
+		return nfaFragment{start: start, end: end}
+
+	case regexPlus:
+		// e+ is built as e e*
+		first := n.thompson(node.children[0])
+		rest := n.thompson(&regexNode{kind: regexStar, children: node.children})
+		n.addEpsilon(first.end, rest.start)
+		return nfaFragment{start: first.start, end: rest.end}
+
+	case regexOptional:
+		start, end := n.newState(), n.newState()
+		body := n.thompson(node.children[0])
+		n.addEpsilon(start, body.start)
+		n.addEpsilon(start, end)
+		n.addEpsilon(body.end, end)
+		return nfaFragment{start: start, end: end}
+	}
+
+	start, end := n.newState(), n.newState()
+	n.addEpsilon(start, end)
+	return nfaFragment{start: start, end: end}
+}
+
+// newSpecNfa builds the automaton that recognizes the union
+// of the patterns. The exit of the i-th pattern accepts i
+func newSpecNfa(patterns []*regexNode) *nfa {
+	n := &nfa{}
+	n.start = n.newState()
+	for idx, pattern := range patterns {
+		fragment := n.thompson(pattern)
+		n.addEpsilon(n.start, fragment.start)
+		n.states[fragment.end].accept = idx
+	}
+	return n
+}
+
+// closure returns, sorted, the states reachable from
+// states without consuming any character
+func (n *nfa) closure(states []int) []int {
+	visited := map[int]bool{}
+	pending := append([]int{}, states...)
+	for len(pending) > 0 {
+		state := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if visited[state] {
+			continue
+		}
+		visited[state] = true
+		pending = append(pending, n.states[state].epsilon...)
+	}
+
+	result := make([]int, 0, len(visited))
+	for state := range visited {
+		result = append(result, state)
+	}
+	sort.Ints(result)
+	return result
+}
+
+// atoms splits the characters read by n into the smallest
+// ranges that every transition either fully contains or
+// doesn't touch at all
+func (n *nfa) atoms() runeSet {
+	sets := []runeSet{}
+	for _, state := range n.states {
+		sets = append(sets, state.set)
+	}
+	return partition(sets)
+}
+
+func stateSetKey(states []int) string {
+	var key strings.Builder
+	for _, state := range states {
+		fmt.Fprintf(&key, "%d,", state)
+	}
+	return key.String()
+}
+
+// subsetDfa is the result of the subset construction. Every
+// state has its transitions keyed by target state, and the
+// patterns that are accepted on it
+type subsetDfa struct {
+	transitions []map[int]runeSet
+	accepts     [][]int
+}
+
+// subsetConstruction turns n into a deterministic automaton
+// whose initial state is 0
+func (n *nfa) subsetConstruction() *subsetDfa {
+	atoms := n.atoms()
+	stateAtoms := make([][]int, len(n.states))
+	for idx, state := range n.states {
+		stateAtoms[idx] = atomsOf(atoms, state.set)
+	}
+
+	dfa := &subsetDfa{}
+	dfaStates := [][]int{}
+	indexes := map[string]int{}
+
+	addState := func(states []int) int {
+		key := stateSetKey(states)
+		if idx, found := indexes[key]; found {
+			return idx
+		}
+		idx := len(dfaStates)
+		indexes[key] = idx
+		dfaStates = append(dfaStates, states)
+
+		accepts := []int{}
+		for _, state := range states {
+			if n.states[state].accept != noAccept {
+				accepts = append(accepts, n.states[state].accept)
+			}
+		}
+		dfa.accepts = append(dfa.accepts, accepts)
+		dfa.transitions = append(dfa.transitions, map[int]runeSet{})
+		return idx
+	}
+
+	addState(n.closure([]int{n.start}))
+	for current := 0; current < len(dfaStates); current++ {
+		moves := map[int][]int{}
+		for _, state := range dfaStates[current] {
+			for _, atom := range stateAtoms[state] {
+				moves[atom] = append(moves[atom], n.states[state].next)
+			}
+		}
+
+		reached := make([]int, 0, len(moves))
+		for atom := range moves {
+			reached = append(reached, atom)
+		}
+		sort.Ints(reached)
+
+		// Many atoms, like the letters of every script, move to
+		// the same states, so their closure is computed only once
+		targets := map[string]int{}
+		ranges := map[int][]runeRange{}
+		for _, atom := range reached {
+			key := stateSetKey(moves[atom])
+			target, found := targets[key]
+			if !found {
+				target = addState(n.closure(moves[atom]))
+				targets[key] = target
+			}
+			ranges[target] = append(ranges[target], atoms[atom])
+		}
+		for target, targetRanges := range ranges {
+			dfa.transitions[current][target] = newRuneSet(targetRanges...)
+		}
+	}
+	return dfa
+}
@@ -0,0 +1,374 @@
+package lexer
+
+import (
+	"fmt"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrorInvalidRegex = fmt.Errorf("invalid regular expression")
+)
+
+// runeRange is the closed interval of characters [lo, hi]
+type runeRange struct {
+	lo rune
+	hi rune
+}
+
+// runeSet is a set of characters kept as sorted
+// ranges that neither overlap nor touch each other
+type runeSet []runeRange
+
+// newRuneSet returns the set with every
+// character covered by the given ranges
+func newRuneSet(ranges ...runeRange) runeSet {
+	sorted := append([]runeRange{}, ranges...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].lo < sorted[j].lo
+	})
+
+	set := runeSet{}
+	for _, r := range sorted {
+		last := len(set) - 1
+		if last >= 0 && r.lo <= set[last].hi+1 {
+			if r.hi > set[last].hi {
+				set[last].hi = r.hi
+			}
+			continue
+		}
+		set = append(set, r)
+	}
+	return set
+}
+
+func singleRune(char rune) runeSet {
+	return runeSet{{lo: char, hi: char}}
+}
+
+// rangeTableSet converts a unicode table,
+// like unicode.L, into a runeSet
+func rangeTableSet(table *unicode.RangeTable) runeSet {
+	ranges := []runeRange{}
+	for _, r := range table.R16 {
+		ranges = append(ranges, strideRanges(rune(r.Lo), rune(r.Hi), rune(r.Stride))...)
+	}
+	for _, r := range table.R32 {
+		ranges = append(ranges, strideRanges(rune(r.Lo), rune(r.Hi), rune(r.Stride))...)
+	}
+	return newRuneSet(ranges...)
+}
+
+func strideRanges(lo, hi, stride rune) []runeRange {
+	if stride == 1 {
+		return []runeRange{{lo: lo, hi: hi}}
+	}
+	ranges := []runeRange{}
+	for char := lo; char <= hi; char += stride {
+		ranges = append(ranges, runeRange{lo: char, hi: char})
+	}
+	return ranges
+}
+
+func (s runeSet) contains(char rune) bool {
+	idx := sort.Search(len(s), func(i int) bool {
+		return s[i].hi >= char
+	})
+	return idx < len(s) && s[idx].lo <= char
+}
+
+func (s runeSet) union(other runeSet) runeSet {
+	return newRuneSet(append(append([]runeRange{}, s...), other...)...)
+}
+
+// complement returns every valid character that is not in s.
+// utf8.RuneError is never part of a complement, so invalid
+// input can't be matched by negated classes
+func (s runeSet) complement() runeSet {
+	set := runeSet{}
+	next := rune(0)
+	for _, r := range s.union(singleRune(utf8.RuneError)) {
+		if r.lo > next {
+			set = append(set, runeRange{lo: next, hi: r.lo - 1})
+		}
+		next = r.hi + 1
+	}
+	if next <= unicode.MaxRune {
+		set = append(set, runeRange{lo: next, hi: unicode.MaxRune})
+	}
+	return set
+}
+
+type regexKind int
+
+const (
+	regexSet regexKind = iota
+	regexEmpty
+	regexConcat
+	regexAlternation
+	regexStar
+	regexPlus
+	regexOptional
+)
+
+// regexNode is a node of the syntax tree of a regular
+// expression. Sets match a single character, while the
+// other kinds combine their children
+type regexNode struct {
+	kind     regexKind
+	set      runeSet
+	children []*regexNode
+}
+
+// regexParser is a recursive descent parser for the
+// regular expressions of the lexer specification:
+//
+//	alternation -> concat ('|' concat)*
+//	concat      -> repeat*
+//	repeat      -> atom ('*' | '+' | '?')*
+//	atom        -> '(' alternation ')' | '[' class ']' | '.' | escape | char
+//
+// Escapes are \n, \t, \r, \d, \s, \p{Name} for unicode
+// categories and scripts, and \c for any metacharacter c
+type regexParser struct {
+	pattern []rune
+	pos     int
+}
+
+// parseRegex returns the syntax tree of pattern
+func parseRegex(pattern string) (*regexNode, error) {
+	p := &regexParser{pattern: []rune(pattern)}
+
+	node, err := p.parseAlternation()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, p.errorf("unexpected '%c'", p.peek())
+	}
+	return node, nil
+}
+
+func (p *regexParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w %q at %d: %s", ErrorInvalidRegex, string(p.pattern), p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *regexParser) done() bool {
+	return p.pos >= len(p.pattern)
+}
+
+func (p *regexParser) peek() rune {
+	return p.pattern[p.pos]
+}
+
+func (p *regexParser) next() rune {
+	char := p.pattern[p.pos]
+	p.pos++
+	return char
+}
+
+func (p *regexParser) parseAlternation() (*regexNode, error) {
+	first, err := p.parseConcat()
+	if err != nil {
+		return nil, err
+	}
+
+	alternatives := []*regexNode{first}
+	for !p.done() && p.peek() == '|' {
+		p.next()
+		alternative, err := p.parseConcat()
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, alternative)
+	}
+
+	if len(alternatives) == 1 {
+		return first, nil
+	}
+	return &regexNode{kind: regexAlternation, children: alternatives}, nil
+}
+
+func (p *regexParser) parseConcat() (*regexNode, error) {
+	sequence := []*regexNode{}
+	for !p.done() && p.peek() != '|' && p.peek() != ')' {
+		node, err := p.parseRepeat()
+		if err != nil {
+			return nil, err
+		}
+		sequence = append(sequence, node)
+	}
+
+	switch len(sequence) {
+	case 0:
+		return &regexNode{kind: regexEmpty}, nil
+	case 1:
+		return sequence[0], nil
+	}
+	return &regexNode{kind: regexConcat, children: sequence}, nil
+}
+
+func (p *regexParser) parseRepeat() (*regexNode, error) {
+	node, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+
+	for !p.done() {
+		var kind regexKind
+		switch p.peek() {
+		case '*':
+			kind = regexStar
+		case '+':
+			kind = regexPlus
+		case '?':
+			kind = regexOptional
+		default:
+			return node, nil
+		}
+		p.next()
+		node = &regexNode{kind: kind, children: []*regexNode{node}}
+	}
+	return node, nil
+}
+
+func (p *regexParser) parseAtom() (*regexNode, error) {
+	char := p.next()
+	switch char {
+	case '(':
+		node, err := p.parseAlternation()
+		if err != nil {
+			return nil, err
+		}
+		if p.done() || p.next() != ')' {
+			return nil, p.errorf("missing ')'")
+		}
+		return node, nil
+	case '[':
+		set, err := p.parseClass()
+		if err != nil {
+			return nil, err
+		}
+		return &regexNode{kind: regexSet, set: set}, nil
+	case '.':
+		return &regexNode{kind: regexSet, set: singleRune('\n').complement()}, nil
+	case '\\':
+		set, err := p.parseEscape()
+		if err != nil {
+			return nil, err
+		}
+		return &regexNode{kind: regexSet, set: set}, nil
+	case '*', '+', '?':
+		return nil, p.errorf("nothing to repeat before '%c'", char)
+	case ')':
+		return nil, p.errorf("unbalanced ')'")
+	}
+	return &regexNode{kind: regexSet, set: singleRune(char)}, nil
+}
+
+// parseClass parses a bracket expression, like [a-z_] or
+// [^"], whose opening bracket was already consumed
+func (p *regexParser) parseClass() (runeSet, error) {
+	negated := false
+	if !p.done() && p.peek() == '^' {
+		p.next()
+		negated = true
+	}
+
+	set := runeSet{}
+	for {
+		if p.done() {
+			return nil, p.errorf("missing ']'")
+		}
+
+		char := p.next()
+		if char == ']' {
+			break
+		}
+
+		if char == '\\' {
+			escaped, err := p.parseEscape()
+			if err != nil {
+				return nil, err
+			}
+			if len(escaped) != 1 || escaped[0].lo != escaped[0].hi {
+				set = set.union(escaped)
+				continue
+			}
+			char = escaped[0].lo
+		}
+
+		if p.pos+1 < len(p.pattern) && p.peek() == '-' && p.pattern[p.pos+1] != ']' {
+			p.next()
+			hi := p.next()
+			if hi == '\\' {
+				escaped, err := p.parseEscape()
+				if err != nil {
+					return nil, err
+				}
+				hi = escaped[0].lo
+			}
+			if hi < char {
+				return nil, p.errorf("invalid range %c-%c", char, hi)
+			}
+			set = set.union(runeSet{{lo: char, hi: hi}})
+			continue
+		}
+		set = set.union(singleRune(char))
+	}
+
+	if negated {
+		return set.complement(), nil
+	}
+	return set, nil
+}
+
+// parseEscape parses what comes after a backslash
+func (p *regexParser) parseEscape() (runeSet, error) {
+	if p.done() {
+		return nil, p.errorf("trailing backslash")
+	}
+
+	char := p.next()
+	switch char {
+	case 'n':
+		return singleRune('\n'), nil
+	case 't':
+		return singleRune('\t'), nil
+	case 'r':
+		return singleRune('\r'), nil
+	case 'd':
+		return runeSet{{lo: '0', hi: '9'}}, nil
+	case 's':
+		return newRuneSet(runeRange{'\t', '\n'}, runeRange{'\r', '\r'}, runeRange{' ', ' '}), nil
+	case 'p':
+		return p.parseUnicodeClass()
+	}
+	return singleRune(char), nil
+}
+
+// parseUnicodeClass parses the {Name} of a \p{Name} escape
+func (p *regexParser) parseUnicodeClass() (runeSet, error) {
+	if p.done() || p.next() != '{' {
+		return nil, p.errorf("expected '{' after \\p")
+	}
+
+	start := p.pos
+	for !p.done() && p.peek() != '}' {
+		p.next()
+	}
+	if p.done() {
+		return nil, p.errorf("missing '}'")
+	}
+	name := string(p.pattern[start:p.pos])
+	p.next()
+
+	if table, found := unicode.Categories[name]; found {
+		return rangeTableSet(table), nil
+	}
+	if table, found := unicode.Scripts[name]; found {
+		return rangeTableSet(table), nil
+	}
+	return nil, p.errorf("unknown unicode class %q", name)
+}
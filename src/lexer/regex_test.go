@@ -0,0 +1,102 @@
+package lexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuneSet(t *testing.T) {
+	r := require.New(t)
+
+	set := newRuneSet(runeRange{'d', 'f'}, runeRange{'a', 'b'}, runeRange{'c', 'c'}, runeRange{'x', 'x'})
+	r.Equal(runeSet{{'a', 'f'}, {'x', 'x'}}, set)
+	r.True(set.contains('a'))
+	r.True(set.contains('e'))
+	r.True(set.contains('x'))
+	r.False(set.contains('g'))
+	r.False(set.contains('z'))
+
+	complement := set.complement()
+	r.False(complement.contains('c'))
+	r.True(complement.contains('g'))
+	r.True(complement.contains('é'))
+	r.False(complement.contains('�'))
+}
+
+func TestParseRegex(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pattern  string
+		matching []string
+		failing  []string
+	}{
+		{
+			name:     "Alternation",
+			pattern:  "<|<=|<>",
+			matching: []string{"<", "<=", "<>"},
+			failing:  []string{"", "<<", "="},
+		},
+		{
+			name:     "Repetitions and optional",
+			pattern:  "[0-9]+(\\.[0-9]+)?",
+			matching: []string{"1", "123", "1.5", "12.50"},
+			failing:  []string{"", ".5", "1.", "1.5.5"},
+		},
+		{
+			name:     "Negated class",
+			pattern:  "\"[^\"]*\"",
+			matching: []string{`""`, `"abc"`, `"média {}"`},
+			failing:  []string{`"`, `"a"b"`},
+		},
+		{
+			name:     "Unicode category",
+			pattern:  "\\p{L}[\\p{L}0-9_]*",
+			matching: []string{"a", "média", "ação_2", "Ωmega"},
+			failing:  []string{"_a", "1a", "a-b"},
+		},
+		{
+			name:     "Escaped metacharacters",
+			pattern:  "[+\\-*/]|\\(|\\)",
+			matching: []string{"+", "-", "*", "/", "(", ")"},
+			failing:  []string{"\\", "()"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			compiled, err := CompileSpec([]TokenSpec{{Class: IDENTIFIER, Regex: tc.pattern}})
+			r.NoError(err)
+
+			for _, text := range tc.matching {
+				r.True(matchesWhole(compiled, text), text)
+			}
+			for _, text := range tc.failing {
+				r.False(matchesWhole(compiled, text), text)
+			}
+		})
+	}
+}
+
+func TestParseRegexErrors(t *testing.T) {
+	for _, pattern := range []string{"(a", "a)", "[a-", "*a", "a\\", "\\p{Nope}", "[z-a]"} {
+		t.Run(pattern, func(t *testing.T) {
+			_, err := parseRegex(pattern)
+			require.ErrorIs(t, err, ErrorInvalidRegex)
+		})
+	}
+}
+
+// matchesWhole returns whether the dft of compiled
+// stops on a final state after reading all of text
+func matchesWhole(compiled *CompiledSpec, text string) bool {
+	dft := compiled.GetDft()
+	for _, char := range text {
+		if _, err := dft.Next(Symbol(char)); err != nil {
+			return false
+		}
+	}
+	return dft.IsFinalState()
+}
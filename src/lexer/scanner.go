@@ -1,12 +1,13 @@
 package lexer
 
 import (
+	"bufio"
 	"errors"
 	"io"
-	"log"
 	errorhandling "mgol-go/src/error_handling"
-	"os"
-	"strings"
+	"mgol-go/src/source"
+	"unicode"
+	"unicode/utf8"
 )
 
 func letterGenerator() []Symbol {
@@ -38,6 +39,21 @@ var (
 	numbers = numGenerator()
 )
 
+// isLetter accepts letters of any script, so identifiers
+// like média or ação are valid
+func isLetter(symbol Symbol) bool {
+	return unicode.IsLetter(rune(symbol))
+}
+
+// isInAlphabet returns whether symbol can appear in a
+// program outside of literals and comments
+func isInAlphabet(symbol Symbol) bool {
+	if symbol >= 0 && symbol < utf8.RuneSelf {
+		return asciiAlphabet[symbol]
+	}
+	return isLetter(symbol)
+}
+
 func flatten(symbols [][]Symbol) []Symbol {
 	result := []Symbol{}
 
@@ -48,12 +64,14 @@ func flatten(symbols [][]Symbol) []Symbol {
 	return result
 }
 
+// alphabet holds the characters of the language besides the
+// letters. The tokens themselves are described in tokens.json
 var (
 	alphabet = flatten([][]Symbol{
 		letters,
 		numbers,
 		{
-			'\n', '\t', ' ',
+			'\n', '\t', '\r', ' ',
 			'_', '+', '-', '*', '/',
 			'>', '<', '=', '{', '}',
 			'(', ')', ';', '"', '.',
@@ -61,372 +79,121 @@ var (
 			'?', '[', ']', '\\',
 		},
 	})
-	states        = []State{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25}
-	finalStates   = []State{1, 2, 4, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 20, 22, 25}
-	transitionMap = map[State][]Transition{
-		0: {
-			{
-				from: 0,
-				to:   1,
-				reading: flatten([][]Symbol{
-					letters,
-				}),
-			},
-			{
-				from: 0,
-				to:   2,
-				reading: flatten([][]Symbol{
-					numbers,
-				}),
-			},
-			{
-				from: 0,
-				to:   8,
-				reading: flatten([][]Symbol{
-					{'<'},
-				}),
-			},
-			{
-				from: 0,
-				to:   10,
-				reading: flatten([][]Symbol{
-					{'>'},
-				}),
-			},
-			{
-				from: 0,
-				to:   12,
-				reading: flatten([][]Symbol{
-					{'='},
-				}),
-			},
-			{
-				from: 0,
-				to:   14,
-				reading: flatten([][]Symbol{
-					{'+', '-', '*', '/'},
-				}),
-			},
-			{
-				from: 0,
-				to:   15,
-				reading: flatten([][]Symbol{
-					{'('},
-				}),
-			},
-			{
-				from: 0,
-				to:   16,
-				reading: flatten([][]Symbol{
-					{')'},
-				}),
-			},
-			{
-				from: 0,
-				to:   17,
-				reading: flatten([][]Symbol{
-					{';'},
-				}),
-			},
-			{
-				from: 0,
-				to:   19,
-				reading: flatten([][]Symbol{
-					{'{'},
-				}),
-			},
-			{
-				from: 0,
-				to:   21,
-				reading: flatten([][]Symbol{
-					{'"'},
-				}),
-			},
-		},
-
-		1: {
-			{
-				from: 1,
-				to:   1,
-				reading: flatten([][]Symbol{
-					letters,
-					numbers,
-					{'_'},
-				}),
-			},
-		},
-
-		2: {
-			{
-				from: 2,
-				to:   2,
-				reading: flatten([][]Symbol{
-					numbers,
-				}),
-			},
-			{
-				from: 2,
-				to:   3,
-				reading: flatten([][]Symbol{
-					{'.'},
-				}),
-			},
-			{
-				from: 2,
-				to:   5,
-				reading: flatten([][]Symbol{
-					{'e', 'E'},
-				}),
-			},
-		},
-
-		3: {
-			{
-				from: 3,
-				to:   4,
-				reading: flatten([][]Symbol{
-					numbers,
-				}),
-			},
-		},
-
-		4: {
-			{
-				from: 4,
-				to:   4,
-				reading: flatten([][]Symbol{
-					numbers,
-				}),
-			},
-			{
-				from: 4,
-				to:   23,
-				reading: flatten([][]Symbol{
-					{'e', 'E'},
-				}),
-			},
-		},
-
-		5: {
-			{
-				from: 5,
-				to:   6,
-				reading: flatten([][]Symbol{
-					{'+', '-'},
-				}),
-			},
-			{
-				from: 5,
-				to:   7,
-				reading: flatten([][]Symbol{
-					numbers,
-				}),
-			},
-		},
-
-		6: {
-			{
-				from: 6,
-				to:   7,
-				reading: flatten([][]Symbol{
-					numbers,
-				}),
-			},
-		},
-
-		7: {
-			{
-				from: 7,
-				to:   7,
-				reading: flatten([][]Symbol{
-					numbers,
-				}),
-			},
-		},
-
-		8: {
-			{
-				from: 8,
-				to:   9,
-				reading: flatten([][]Symbol{
-					{'>', '='},
-				}),
-			},
-			{
-				from: 8,
-				to:   13,
-				reading: flatten([][]Symbol{
-					{'-'},
-				}),
-			},
-		},
-
-		10: {
-			{
-				from: 10,
-				to:   11,
-				reading: flatten([][]Symbol{
-					{'='},
-				}),
-			},
-		},
-
-		19: {
-			{
-				from: 19,
-				to:   19,
-				reading: flatten([][]Symbol{
-					letters,
-					numbers,
-					{'\t', ' ', '_', '+', '-', '*', '/', '>', '<', '=', '{', '(', ')', ';', '"', '.', ':', ',', '!', '?', '[', ']', '\\'},
-				}),
-			},
-			{
-				from: 19,
-				to:   20,
-				reading: flatten([][]Symbol{
-					{'}'},
-				}),
-			},
-		},
-
-		21: {
-			{
-				from: 21,
-				to:   21,
-				reading: flatten([][]Symbol{
-					letters,
-					numbers,
-					{'\t', ' ', '_', '+', '-', '*', '/', '>', '<', '=', '{', '}', '(', ')', ';', '.', ':', ',', '!', '?', '[', ']', '\\'},
-				}),
-			},
-			{
-				from: 21,
-				to:   22,
-				reading: flatten([][]Symbol{
-					{'"'},
-				}),
-			},
-		},
-
-		23: {
-			{
-				from: 23,
-				to:   24,
-				reading: flatten([][]Symbol{
-					{'+', '-'},
-				}),
-			},
-			{
-				from: 23,
-				to:   25,
-				reading: flatten([][]Symbol{
-					numbers,
-				}),
-			},
-		},
-
-		24: {
-			{
-				from: 24,
-				to:   25,
-				reading: flatten([][]Symbol{
-					numbers,
-				}),
-			},
-		},
-
-		25: {
-			{
-				from: 25,
-				to:   25,
-				reading: flatten([][]Symbol{
-					numbers,
-				}),
-			},
-		},
-	}
-	stateToTokenClassMap = map[State]TokenClass{
-		1:  IDENTIFIER,
-		2:  NUM,
-		4:  NUM,
-		7:  NUM,
-		8:  REL_OP,
-		9:  REL_OP,
-		10: REL_OP,
-		11: REL_OP,
-		12: REL_OP,
-		13: ATTR,
-		14: ARIT_OP,
-		15: OPEN_PAR,
-		16: CLOSE_PAR,
-		17: SEMICOLON,
-		20: COMMENT,
-		22: LITERAL_CONST,
-		25: NUM,
-	}
-	numericTypes = map[State]DataType{
-		2:  INTEGER,
-		4:  REAL,
-		7:  INTEGER,
-		25: REAL,
-	}
+	// asciiAlphabet is the alphabet as a lookup table, since
+	// every character read is checked against it
+	asciiAlphabet = func() [utf8.RuneSelf]bool {
+		table := [utf8.RuneSelf]bool{}
+		for _, symbol := range alphabet {
+			table[symbol] = true
+		}
+		return table
+	}()
 )
 
+// pushedSymbol is a character given back to the input
+// along with the positions around it
+type pushedSymbol struct {
+	char  rune
+	start source.Position
+	end   source.Position
+}
+
 type Scanner struct {
-	file                 *os.File
-	lexemBuffer          []byte
-	currentLineFile      int
-	currentColumnFile    int
-	dft                  Dft
-	stateToTokenClassMap map[State]TokenClass
-	symbolsToIgnore      []Symbol
-	symbolTable          *SymbolTable
+	reader           *bufio.Reader
+	pushback         []pushedSymbol
+	lexemBuffer      []rune
+	position         source.Position
+	lastCharPosition source.Position
+	tokenStart       source.Position
+	dft              Dft
+	spec             *CompiledSpec
+	symbolTable      *SymbolTable
+	diagnostics      *errorhandling.Collector
+	// failed tells the input could not be read,
+	// so the scanner only returns EOF afterwards
+	failed bool
 }
 
-func NewScanner(file *os.File, symbolTable *SymbolTable) *Scanner {
-	dft, err := NewDft(alphabet, states, 0, finalStates, transitionMap)
-	if err != nil {
-		log.Fatal("Failed to create DFT:", err)
-	}
+// NewScanner returns a Scanner that reads the source
+// program from reader and reports lexical errors to
+// diagnostics. The reader is buffered internally, so
+// there is no need to wrap it in a bufio.Reader
+func NewScanner(reader io.Reader, symbolTable *SymbolTable, diagnostics *errorhandling.Collector) *Scanner {
+	return NewScannerWithSpec(reader, symbolTable, diagnostics, GetDefaultSpec())
+}
 
+// NewScannerWithSpec does the same as NewScanner but
+// recognizes the tokens described by spec
+func NewScannerWithSpec(reader io.Reader, symbolTable *SymbolTable, diagnostics *errorhandling.Collector, spec *CompiledSpec) *Scanner {
 	return &Scanner{
-		file:                 file,
-		lexemBuffer:          []byte{},
-		currentLineFile:      1,
-		currentColumnFile:    0,
-		dft:                  *dft,
-		stateToTokenClassMap: stateToTokenClassMap,
-		symbolsToIgnore:      []Symbol{'\n', ' ', '\t'},
-		symbolTable:          symbolTable,
+		reader:           bufio.NewReader(reader),
+		pushback:         []pushedSymbol{},
+		lexemBuffer:      []rune{},
+		position:         source.StartOfFile,
+		lastCharPosition: source.StartOfFile,
+		tokenStart:       source.StartOfFile,
+		dft:              spec.GetDft(),
+		spec:             spec,
+		symbolTable:      symbolTable,
+		diagnostics:      diagnostics,
 	}
 }
 
-func (s *Scanner) getTokenClass(state State) TokenClass {
-	return s.stateToTokenClassMap[state]
-}
-
-// Recognizes if a number is integer or real and
-// changes the dataType accordingly
-func (s *Scanner) updateDataType(token *Token) {
-	switch token.class {
-	case NUM:
-		token.dataType = numericTypes[s.dft.currentState]
-	case LITERAL_CONST:
-		token.dataType = LITERAL
-	default:
-		token.dataType = NULL
-	}
+// getTokenSpec returns the spec of the token recognized
+// by the dft on its current state
+func (s *Scanner) getTokenSpec() TokenSpec {
+	spec, _ := s.spec.GetTokenSpec(s.dft.GetCurrentState())
+	return spec
 }
 
 func (s *Scanner) clearLexemBuffer() {
-	s.lexemBuffer = []byte{}
+	s.lexemBuffer = s.lexemBuffer[:0]
 }
 
 func (s *Scanner) GetSymbolTable() *SymbolTable {
 	return s.symbolTable
 }
 
+func (s *Scanner) GetDiagnostics() *errorhandling.Collector {
+	return s.diagnostics
+}
+
+// reportError reports the invalid lexem, where
+// span is the character the scanner stopped at
+func (s *Scanner) reportError(span source.Span, lexem string) {
+	s.diagnostics.Report(errorhandling.NewLexicalError(span, lexem))
+}
+
+// read returns the next UTF-8 character of the input and
+// moves the current position after it. Characters previously
+// given back with unread are returned first, in the reverse
+// order they were given back. Invalid encodings are returned
+// as utf8.RuneError
+func (s *Scanner) read() (rune, error) {
+	if len(s.pushback) > 0 {
+		pushed := s.pushback[len(s.pushback)-1]
+		s.pushback = s.pushback[:len(s.pushback)-1]
+		s.lastCharPosition = pushed.start
+		s.position = pushed.end
+		return pushed.char, nil
+	}
+
+	char, size, err := s.reader.ReadRune()
+	if err != nil {
+		return char, err
+	}
+	s.lastCharPosition = s.position
+	s.position = s.position.Advance(char, size)
+	return char, nil
+}
+
+// unread gives char, which started at start, back to the
+// input, so the next call to read returns it again
+func (s *Scanner) unread(char rune, start source.Position) {
+	s.pushback = append(s.pushback, pushedSymbol{char: char, start: start, end: s.position})
+	s.position = start
+}
+
 // reset clears the lexem buffer
 // and resets the head of the dft
 func (s *Scanner) reset() {
@@ -435,123 +202,138 @@ func (s *Scanner) reset() {
 }
 
 // resetAndRewind does the same as
-// reset but rewind the head of the
-// file as well
-func (s *Scanner) resetAndRewind() {
+// reset but gives the lookahead
+// character back to the input
+func (s *Scanner) resetAndRewind(lookahead rune, start source.Position) {
 	s.reset()
-	s.file.Seek(-1, os.SEEK_CUR)
+	s.unread(lookahead, start)
+}
+
+// isIgnoring returns whether the dft stopped on a
+// final state of a skipped token, like whitespace
+func (s *Scanner) isIgnoring() bool {
+	spec, found := s.spec.GetTokenSpec(s.dft.GetCurrentState())
+	return found && spec.Ignore
+}
+
+// withSpan returns a copy of token that
+// was read between start and end
+func withSpan(token Token, start, end source.Position) Token {
+	token.SetSpan(source.NewSpan(start, end))
+	return token
+}
+
+// newToken builds the token recognized by the dft with the
+// lexem buffer. Identifiers are resolved on the symbol table,
+// so reserved words get their own class
+func (s *Scanner) newToken(start, end source.Position) Token {
+	spec := s.getTokenSpec()
+	token := NewToken(spec.Class, string(s.lexemBuffer), spec.DataType)
+
+	if token.class == IDENTIFIER {
+		token = s.symbolTable.Insert(token.lexeme, token)
+	}
+	return withSpan(token, start, end)
 }
 
-// Scan reads the Scanner file until finds a Token or an error.
+// Scan reads the Scanner input until finds a Token or an error.
 // If it finds a Token it returns the reconized token, otherwhise
-// just returns an error Token and shows to the user the error
-// message related
-func (s *Scanner) Scan() (Token, int, int) {
-	readBuffer := make([]byte, 1)
+// just returns an error Token and reports the related error
+// to the diagnostics collector. Every returned token carries the span it
+// was read from, EOF included. When the input fails to be read, the
+// failure is reported as well and only EOF is returned from then on
+func (s *Scanner) Scan() Token {
+	if s.failed {
+		return withSpan(EOF_TOKEN, s.position, s.position)
+	}
 
 	for {
-		n, err := s.file.Read(readBuffer)
-		currChar := readBuffer[0]
+		charStart := s.position
+		currChar, err := s.read()
 		currSymbol := Symbol(currChar)
 
-		s.currentColumnFile += n
+		// The program ends where it fails to be read
+		if err != nil && err != io.EOF {
+			s.diagnostics.Report(errorhandling.NewReadError(source.NewSpan(charStart, charStart), err))
+			s.failed = true
+			err = io.EOF
+		}
 
 		if err == io.EOF && len(s.lexemBuffer) == 0 {
-			return EOF_TOKEN, 0, 0
+			return withSpan(EOF_TOKEN, charStart, charStart)
 		}
 
 		if err == io.EOF && len(s.lexemBuffer) != 0 {
-			if ContainsByte(s.lexemBuffer, '{') && !ContainsByte(s.lexemBuffer, '}') {
-				errorhandling.NewLexicalError(s.currentLineFile, s.currentColumnFile, string(s.lexemBuffer))
+			if !s.dft.IsFinalState() {
+				s.reportError(source.NewSpan(s.lastCharPosition, charStart), string(s.lexemBuffer))
 				s.reset()
-				return ERROR_TOKEN, 0, 0
+				return withSpan(ERROR_TOKEN, s.tokenStart, charStart)
 			}
 
-			numberOfQuotation := strings.Count(string(s.lexemBuffer), "\"")
-			if numberOfQuotation == 1 {
-				errorhandling.NewLexicalError(s.currentLineFile, s.currentColumnFile, string(s.lexemBuffer))
+			spec := s.getTokenSpec()
+			if spec.Ignore {
 				s.reset()
-				return ERROR_TOKEN, 0, 0
+				return withSpan(EOF_TOKEN, charStart, charStart)
 			}
-
-			tokenClass := s.getTokenClass(s.dft.GetCurrentState())
-			if tokenClass == COMMENT {
+			if spec.Class == COMMENT {
 				s.reset()
-				return COMMENT_TOKEN, 0, 0
+				return withSpan(COMMENT_TOKEN, s.tokenStart, charStart)
 			}
-			lexem := s.lexemBuffer
-			token := NewToken(tokenClass, string(lexem), NULL)
-			s.updateDataType(&token)
 
+			token := s.newToken(s.tokenStart, charStart)
 			s.reset()
-
-			if token.class == IDENTIFIER {
-				return s.symbolTable.Insert(token.lexeme, token), s.currentLineFile, s.currentColumnFile
-			}
-			return token, s.currentLineFile, s.currentColumnFile
+			return token
 		}
 
-		if !ContainsSymbol(alphabet, currSymbol) || !ContainsByte(s.lexemBuffer, '{') && currChar == '}' {
-			errorhandling.NewLexicalError(s.currentLineFile, s.currentColumnFile, string(s.lexemBuffer)+string(currChar))
-			s.reset()
-			return ERROR_TOKEN, 0, 0
+		if s.dft.GetCurrentState() == s.dft.initialState {
+			s.tokenStart = charStart
 		}
 
-		previousColumnLine := s.currentColumnFile
-		if currChar == '\n' {
-			s.currentLineFile += 1
-			s.currentColumnFile = 0
+		// A character out of the language, or a '}' that doesn't
+		// close a comment, makes the whole lexem invalid
+		invalidChar := !isInAlphabet(currSymbol) || currChar == '}' && !ContainsRune(s.lexemBuffer, '{')
+		if invalidChar && !s.dft.transitionExists(currSymbol) && !s.isIgnoring() {
+			s.reportError(source.NewSpan(charStart, s.position), string(s.lexemBuffer)+string(currChar))
+			s.reset()
+			return withSpan(ERROR_TOKEN, s.tokenStart, s.position)
 		}
 
 		_, err = s.dft.Next(currSymbol)
 
 		if errors.Is(err, ErrorTransitionDoesNotExist) && s.dft.IsFinalState() {
-			tokenClass := s.getTokenClass(s.dft.GetCurrentState())
-			if tokenClass == COMMENT {
-				s.resetAndRewind()
-				return COMMENT_TOKEN, 0, 0
+			spec := s.getTokenSpec()
+			if spec.Ignore {
+				s.resetAndRewind(currChar, charStart)
+				continue
 			}
-			lexem := s.lexemBuffer
-			token := NewToken(tokenClass, string(lexem), NULL)
-			s.updateDataType(&token)
-
-			s.resetAndRewind()
-
-			s.currentColumnFile -= n
-			if currChar == '\n' {
-				s.currentLineFile -= 1
+			if spec.Class == COMMENT {
+				s.resetAndRewind(currChar, charStart)
+				return withSpan(COMMENT_TOKEN, s.tokenStart, charStart)
 			}
 
-			if token.class == IDENTIFIER {
-				return s.symbolTable.Insert(token.lexeme, token), s.currentLineFile, previousColumnLine - 1
-			}
-			return token, s.currentLineFile, previousColumnLine - 1
+			token := s.newToken(s.tokenStart, charStart)
+			s.resetAndRewind(currChar, charStart)
+			return token
 		}
 
 		if errors.Is(err, ErrorTransitionDoesNotExist) && !s.dft.IsFinalState() {
-			if currChar == ' ' || currChar == '\n' || currChar == '\t' {
-				continue
-			}
-
-			if len(string(s.lexemBuffer)) == 0 {
-				errorhandling.NewLexicalError(s.currentLineFile, s.currentColumnFile, string(currChar))
+			if len(s.lexemBuffer) == 0 {
+				s.reportError(source.NewSpan(charStart, s.position), string(currChar))
 			} else {
-				errorhandling.NewLexicalError(s.currentLineFile, s.currentColumnFile, string(s.lexemBuffer))
+				s.reportError(source.NewSpan(charStart, s.position), string(s.lexemBuffer))
 			}
 
 			s.clearLexemBuffer()
 			if s.dft.currentState != s.dft.initialState {
-				s.file.Seek(-1, os.SEEK_CUR)
+				s.unread(currChar, charStart)
+				s.dft.Reset()
+				return withSpan(ERROR_TOKEN, s.tokenStart, charStart)
 			}
 			s.dft.Reset()
 
-			return ERROR_TOKEN, 0, 0
+			return withSpan(ERROR_TOKEN, s.tokenStart, s.position)
 		}
 
-		if !ContainsSymbol(s.symbolsToIgnore, currSymbol) {
-			s.lexemBuffer = append(s.lexemBuffer, currChar)
-		} else if ContainsByte(s.lexemBuffer, '"') || ContainsByte(s.lexemBuffer, '{') {
-			s.lexemBuffer = append(s.lexemBuffer, currChar)
-		}
+		s.lexemBuffer = append(s.lexemBuffer, currChar)
 	}
 }
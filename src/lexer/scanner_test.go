@@ -1,22 +1,23 @@
 package lexer
 
 import (
-	"bytes"
+	"errors"
 	"io"
 	"io/ioutil"
-	"log"
-	"os"
+	errorhandling "mgol-go/src/error_handling"
+	"mgol-go/src/source"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/require"
 )
 
-func captureOutput(f func()) string {
-	var buf bytes.Buffer
-	log.SetOutput(&buf)
-	f()
-	log.SetOutput(os.Stderr)
-	return buf.String()
+// withoutSpan drops the span of token, so it can be
+// compared with tokens built by NewToken
+func withoutSpan(token Token) Token {
+	token.SetSpan(source.Span{})
+	return token
 }
 
 func TestScanNumToken(t *testing.T) {
@@ -168,10 +169,10 @@ func TestScanNumToken(t *testing.T) {
 
 			file.Seek(0, io.SeekStart)
 
-			scanner := NewScanner(file, GetSymbolTableInstance())
+			scanner := NewScanner(file, GetSymbolTableInstance(), errorhandling.NewCollector())
 			tokens := []Token{}
 			for {
-				token, _, _ := scanner.Scan()
+				token := withoutSpan(scanner.Scan())
 				if token == EOF_TOKEN {
 					break
 				}
@@ -209,6 +210,16 @@ func TestScanIdToken(t *testing.T) {
 			preparedText:  "id_123_id",
 			expectedToken: NewToken(IDENTIFIER, "id_123_id", NULL),
 		},
+		{
+			name:          "Identifier with accents",
+			preparedText:  "média_1",
+			expectedToken: NewToken(IDENTIFIER, "média_1", NULL),
+		},
+		{
+			name:          "Identifier starting with an accented letter",
+			preparedText:  "ação",
+			expectedToken: NewToken(IDENTIFIER, "ação", NULL),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -222,8 +233,8 @@ func TestScanIdToken(t *testing.T) {
 
 			file.Seek(0, io.SeekStart)
 
-			scanner := NewScanner(file, GetSymbolTableInstance())
-			token, _, _ := scanner.Scan()
+			scanner := NewScanner(file, GetSymbolTableInstance(), errorhandling.NewCollector())
+			token := withoutSpan(scanner.Scan())
 
 			require.Equal(t, tc.expectedToken, token)
 		})
@@ -262,6 +273,14 @@ func TestScanCommentToken(t *testing.T) {
 				EOF_TOKEN,
 			},
 		},
+		{
+			name:         "Comment with accents and symbols",
+			preparedText: "{calcula a média em % ☺}",
+			expectedToken: []Token{
+				COMMENT_TOKEN,
+				EOF_TOKEN,
+			},
+		},
 		{
 			name:         "Comment not closed",
 			preparedText: "{{abab",
@@ -292,10 +311,10 @@ func TestScanCommentToken(t *testing.T) {
 
 			file.Seek(0, io.SeekStart)
 
-			scanner := NewScanner(file, GetSymbolTableInstance())
+			scanner := NewScanner(file, GetSymbolTableInstance(), errorhandling.NewCollector())
 
 			for _, expectedToken := range tc.expectedToken {
-				token, _, _ := scanner.Scan()
+				token := withoutSpan(scanner.Scan())
 				require.Equal(t, expectedToken, token)
 			}
 		})
@@ -313,6 +332,16 @@ func TestScanLiteralConstantToken(t *testing.T) {
 			preparedText:  `"This is a constant literal"`,
 			expectedToken: NewToken(LITERAL_CONST, `"This is a constant literal"`, LITERAL),
 		},
+		{
+			name:          "Constant Literal with accents",
+			preparedText:  `"Informe a média: 100% ✓"`,
+			expectedToken: NewToken(LITERAL_CONST, `"Informe a média: 100% ✓"`, LITERAL),
+		},
+		{
+			name:          "Constant Literal with brackets",
+			preparedText:  `"{a} [b]"`,
+			expectedToken: NewToken(LITERAL_CONST, `"{a} [b]"`, LITERAL),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -326,8 +355,8 @@ func TestScanLiteralConstantToken(t *testing.T) {
 
 			file.Seek(0, io.SeekStart)
 
-			scanner := NewScanner(file, GetSymbolTableInstance())
-			token, _, _ := scanner.Scan()
+			scanner := NewScanner(file, GetSymbolTableInstance(), errorhandling.NewCollector())
+			token := withoutSpan(scanner.Scan())
 
 			require.Equal(t, tc.expectedToken, token)
 		})
@@ -458,7 +487,7 @@ func TestScanGeneralCases(t *testing.T) {
 				NewToken(IDENTIFIER, "B", NULL),
 				NewToken(REL_OP, "<>", NULL),
 				NewToken(IDENTIFIER, "C", NULL),
-				NewToken(ARIT_OP, "/", NULL),
+				NewToken(MUL_OP, "/", NULL),
 				NewToken(IDENTIFIER, "D", NULL),
 				CLOSE_PAR_TOKEN,
 				SEMICOLON_TOKEN,
@@ -476,13 +505,43 @@ func TestScanGeneralCases(t *testing.T) {
 				NewToken(IDENTIFIER, "B", NULL),
 				NewToken(REL_OP, "<>", NULL),
 				NewToken(IDENTIFIER, "C", NULL),
-				NewToken(ARIT_OP, "/", NULL),
+				NewToken(MUL_OP, "/", NULL),
 				NewToken(IDENTIFIER, "D", NULL),
 				CLOSE_PAR_TOKEN,
 				SEMICOLON_TOKEN,
 				EOF_TOKEN,
 			},
 		},
+		{
+			name:         "Declaration list with an initializer",
+			preparedText: "inteiro A,B <- 1;",
+			expectedToken: []Token{
+				NewToken("inteiro", "inteiro", "inteiro"),
+				NewToken(IDENTIFIER, "A", NULL),
+				NewToken(COMMA, ",", NULL),
+				NewToken(IDENTIFIER, "B", NULL),
+				ATTR_TOKEN,
+				NewToken(NUM, "1", INTEGER),
+				SEMICOLON_TOKEN,
+				EOF_TOKEN,
+			},
+		},
+		{
+			name:         "Element of a matrix",
+			preparedText: "m[1][I+1]",
+			expectedToken: []Token{
+				NewToken(IDENTIFIER, "m", NULL),
+				NewToken(OPEN_BRACKET, "[", NULL),
+				NewToken(NUM, "1", INTEGER),
+				NewToken(CLOSE_BRACKET, "]", NULL),
+				NewToken(OPEN_BRACKET, "[", NULL),
+				NewToken(IDENTIFIER, "I", NULL),
+				NewToken(ARIT_OP, "+", NULL),
+				NewToken(NUM, "1", INTEGER),
+				NewToken(CLOSE_BRACKET, "]", NULL),
+				EOF_TOKEN,
+			},
+		},
 		{
 			name:         "Escreva with jump line",
 			preparedText: `escreva "\nA=\n";`,
@@ -511,17 +570,17 @@ func TestScanGeneralCases(t *testing.T) {
 
 			file.Seek(0, io.SeekStart)
 
-			scanner := NewScanner(file, GetSymbolTableInstance())
+			scanner := NewScanner(file, GetSymbolTableInstance(), errorhandling.NewCollector())
 
 			for _, expectedToken := range tc.expectedToken {
-				token, _, _ := scanner.Scan()
+				token := withoutSpan(scanner.Scan())
 				require.Equal(t, expectedToken, token)
 			}
 		})
 	}
 }
 
-func TestStdoutErrorLog(t *testing.T) {
+func TestLexicalErrorDiagnostics(t *testing.T) {
 	testCases := []struct {
 		name           string
 		preparedText   string
@@ -575,6 +634,22 @@ func TestStdoutErrorLog(t *testing.T) {
 				"erro na linha 4 coluna 8, palavra $ inexistente na linguagem",
 			},
 		},
+		{
+			name:         "Columns are counted in characters",
+			preparedText: "média ação %",
+			expectedOutput: []string{
+				"",
+				"",
+				"erro na linha 1 coluna 12, palavra % inexistente na linguagem",
+			},
+		},
+		{
+			name:         "Invalid UTF-8 inside a literal",
+			preparedText: "\"a\xffb\"",
+			expectedOutput: []string{
+				"erro na linha 1 coluna 3, literal \"a\uFFFD inválido",
+			},
+		},
 		{
 			name:         "Malformated number",
 			preparedText: "1.e3",
@@ -588,7 +663,7 @@ func TestStdoutErrorLog(t *testing.T) {
 			preparedText: "1..0",
 			expectedOutput: []string{
 				"erro na linha 1 coluna 3, número 1. inválido",
-				"erro na linha 1 coluna 4, palavra . inexistente na linguagem",
+				"erro na linha 1 coluna 3, palavra . inexistente na linguagem",
 			},
 		},
 		{
@@ -607,6 +682,25 @@ func TestStdoutErrorLog(t *testing.T) {
 				"",
 			},
 		},
+		{
+			name:         "Literal not closed at the end of the line",
+			preparedText: "\"ab\ncd",
+			expectedOutput: []string{
+				"erro na linha 1 coluna 4, literal \"ab inválido",
+				"",
+				"",
+			},
+		},
+		{
+			name:         "Comment not closed at the end of the line",
+			preparedText: "{ab\nA;",
+			expectedOutput: []string{
+				"erro na linha 1 coluna 4, comentário {ab inválido",
+				"",
+				"",
+				"",
+			},
+		},
 		{
 			name:         "State 0 with no transition and lexembuffer empty",
 			preparedText: "!!",
@@ -642,16 +736,152 @@ func TestStdoutErrorLog(t *testing.T) {
 
 			file.Seek(0, io.SeekStart)
 
-			scanner := NewScanner(file, GetSymbolTableInstance())
+			scanner := NewScanner(file, GetSymbolTableInstance(), errorhandling.NewCollector())
 
 			for _, expectedOutput := range tc.expectedOutput {
-				output := captureOutput(func() { scanner.Scan() })
-				// Remove date, hour and line break
-				if output != "" {
-					output = output[20 : len(output)-1]
+				reported := scanner.GetDiagnostics().Len()
+				scanner.Scan()
+
+				output := ""
+				if scanner.GetDiagnostics().Len() > reported {
+					output = scanner.GetDiagnostics().Diagnostics()[reported].String()
 				}
 				require.Equal(t, expectedOutput, output)
 			}
 		})
 	}
 }
+
+func TestScanFromReader(t *testing.T) {
+	testCases := []struct {
+		name          string
+		preparedText  string
+		expectedToken []Token
+	}{
+		{
+			name:         "Assignment with lookahead on every token",
+			preparedText: "A<-1.5e-3;",
+			expectedToken: []Token{
+				NewToken(IDENTIFIER, "A", NULL),
+				ATTR_TOKEN,
+				NewToken(NUM, "1.5e-3", REAL),
+				SEMICOLON_TOKEN,
+				EOF_TOKEN,
+			},
+		},
+		{
+			name:         "Tokens split across lines",
+			preparedText: "A\n<\n-\nB",
+			expectedToken: []Token{
+				NewToken(IDENTIFIER, "A", NULL),
+				NewToken(REL_OP, "<", NULL),
+				NewToken(ARIT_OP, "-", NULL),
+				NewToken(IDENTIFIER, "B", NULL),
+				EOF_TOKEN,
+			},
+		},
+		{
+			name:         "Error rewinds the lookahead",
+			preparedText: "1.;",
+			expectedToken: []Token{
+				ERROR_TOKEN,
+				SEMICOLON_TOKEN,
+				EOF_TOKEN,
+			},
+		},
+	}
+
+	symbolTable := GetSymbolTableInstance()
+	defer symbolTable.Cleanup()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scanner := NewScanner(strings.NewReader(tc.preparedText), symbolTable, errorhandling.NewCollector())
+
+			for _, expectedToken := range tc.expectedToken {
+				token := withoutSpan(scanner.Scan())
+				require.Equal(t, expectedToken, token)
+			}
+		})
+	}
+}
+
+func pos(line, column, offset int) source.Position {
+	return source.Position{Line: line, Column: column, Offset: offset}
+}
+
+func TestScanSpans(t *testing.T) {
+	testCases := []struct {
+		name          string
+		preparedText  string
+		expectedSpans []source.Span
+	}{
+		{
+			name:         "Tokens across lines",
+			preparedText: "A\n\nBC <- 1;",
+			expectedSpans: []source.Span{
+				source.NewSpan(pos(1, 1, 0), pos(1, 2, 1)),
+				source.NewSpan(pos(3, 1, 3), pos(3, 3, 5)),
+				source.NewSpan(pos(3, 4, 6), pos(3, 6, 8)),
+				source.NewSpan(pos(3, 7, 9), pos(3, 8, 10)),
+				source.NewSpan(pos(3, 8, 10), pos(3, 9, 11)),
+				source.NewSpan(pos(3, 9, 11), pos(3, 9, 11)),
+			},
+		},
+		{
+			name:         "Columns count characters and offsets count bytes",
+			preparedText: `é "ação" {ó}`,
+			expectedSpans: []source.Span{
+				source.NewSpan(pos(1, 1, 0), pos(1, 2, 2)),
+				source.NewSpan(pos(1, 3, 3), pos(1, 9, 11)),
+				source.NewSpan(pos(1, 10, 12), pos(1, 13, 16)),
+				source.NewSpan(pos(1, 13, 16), pos(1, 13, 16)),
+			},
+		},
+		{
+			name:         "Errors",
+			preparedText: "1.e $\n\"ab",
+			expectedSpans: []source.Span{
+				source.NewSpan(pos(1, 1, 0), pos(1, 3, 2)),
+				source.NewSpan(pos(1, 3, 2), pos(1, 4, 3)),
+				source.NewSpan(pos(1, 5, 4), pos(1, 6, 5)),
+				source.NewSpan(pos(2, 1, 6), pos(2, 4, 9)),
+				source.NewSpan(pos(2, 4, 9), pos(2, 4, 9)),
+			},
+		},
+	}
+
+	symbolTable := GetSymbolTableInstance()
+	defer symbolTable.Cleanup()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scanner := NewScanner(strings.NewReader(tc.preparedText), symbolTable, errorhandling.NewCollector())
+
+			for _, expectedSpan := range tc.expectedSpans {
+				token := scanner.Scan()
+				require.Equal(t, expectedSpan, token.GetSpan(), token.String())
+			}
+		})
+	}
+}
+
+func TestScanReadFailure(t *testing.T) {
+	r := require.New(t)
+	reader := io.MultiReader(strings.NewReader("A <- B;"), iotest.ErrReader(errors.New("disco removido")))
+	diagnostics := errorhandling.NewCollector()
+	scanner := NewScanner(reader, GetSymbolTableInstance(), diagnostics)
+
+	lexems := []string{}
+	for token := scanner.Scan(); !token.IsClass(EOF); token = scanner.Scan() {
+		lexems = append(lexems, token.GetLexem())
+	}
+	r.Equal([]string{"A", "<-", "B", ";"}, lexems)
+	r.True(scanner.Scan().IsClass(EOF))
+
+	reported := diagnostics.Sorted()
+	r.Len(reported, 1)
+	r.Equal(errorhandling.ReadFailureCode, reported[0].Code)
+	r.Equal("falha ao ler o programa: disco removido", reported[0].Message)
+	r.Equal("1:8-1:8", reported[0].Span.String())
+}
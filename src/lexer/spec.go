@@ -0,0 +1,191 @@
+package lexer
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+)
+
+var (
+	ErrorEmptySpec = fmt.Errorf("the lexer specification has no token classes")
+)
+
+//go:embed tokens.json
+var defaultSpecFile []byte
+
+// TokenSpec describes one class of tokens of the language.
+// When more than one spec matches the same lexem the one
+// with the highest priority wins, and ties are broken by
+// the order of the specs. Tokens of ignored specs, like
+// whitespace, are consumed but never returned by Scan
+type TokenSpec struct {
+	Class    TokenClass `json:"class"`
+	Regex    string     `json:"regex"`
+	DataType DataType   `json:"type,omitempty"`
+	Ignore   bool       `json:"ignore,omitempty"`
+	Priority int        `json:"priority,omitempty"`
+}
+
+// CompiledSpec is a lexer specification turned into a
+// deterministic automaton, where every final state knows
+// which TokenSpec it recognizes
+type CompiledSpec struct {
+	dft     *Dft
+	accepts map[State]TokenSpec
+	specs   []TokenSpec
+}
+
+var defaultSpecInstance *CompiledSpec
+
+// LoadLexerSpec reads a lexer specification, a JSON
+// array of TokenSpec, from reader
+func LoadLexerSpec(reader io.Reader) ([]TokenSpec, error) {
+	specs := []TokenSpec{}
+	if err := json.NewDecoder(reader).Decode(&specs); err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
+
+// CompileSpec builds the dft of a lexer specification. Each
+// regular expression goes through the Thompson construction,
+// the resulting automaton is made deterministic with the
+// subset construction and then minimized
+func CompileSpec(specs []TokenSpec) (*CompiledSpec, error) {
+	if len(specs) == 0 {
+		return nil, ErrorEmptySpec
+	}
+
+	patterns := []*regexNode{}
+	for _, spec := range specs {
+		pattern, err := parseRegex(spec.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("token class %s: %w", spec.Class, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	subset := newSpecNfa(patterns).subsetConstruction()
+
+	states := []State{}
+	finalStates := []State{}
+	transitionMap := map[State][]Transition{}
+	accepts := map[State]TokenSpec{}
+
+	for idx := range subset.transitions {
+		state := State(idx)
+		states = append(states, state)
+
+		if len(subset.accepts[idx]) > 0 {
+			finalStates = append(finalStates, state)
+			accepts[state] = chooseSpec(specs, subset.accepts[idx])
+		}
+
+		for target, set := range subset.transitions[idx] {
+			transitionMap[state] = append(transitionMap[state], Transition{
+				from:   state,
+				to:     State(target),
+				ranges: set,
+			})
+		}
+		sort.Slice(transitionMap[state], func(i, j int) bool {
+			return transitionMap[state][i].to < transitionMap[state][j].to
+		})
+	}
+
+	dft, err := NewDft(states, 0, finalStates, transitionMap)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[State]string{}
+	for state, spec := range accepts {
+		labels[state] = fmt.Sprintf("%s %s %t", spec.Class, spec.DataType, spec.Ignore)
+	}
+	minimal, mapping := dft.Minimize(labels)
+
+	minimalAccepts := map[State]TokenSpec{}
+	for state, spec := range accepts {
+		minimalAccepts[mapping[state]] = spec
+	}
+
+	return &CompiledSpec{
+		dft:     minimal,
+		accepts: minimalAccepts,
+		specs:   specs,
+	}, nil
+}
+
+// GetDefaultSpec returns the compiled specification
+// of mgol, embedded from tokens.json
+func GetDefaultSpec() *CompiledSpec {
+	if defaultSpecInstance == nil {
+		specs, err := LoadLexerSpec(bytes.NewReader(defaultSpecFile))
+		if err != nil {
+			log.Fatalf("Failed to load the lexer specification: %v", err)
+		}
+
+		defaultSpecInstance, err = CompileSpec(specs)
+		if err != nil {
+			log.Fatalf("Failed to compile the lexer specification: %v", err)
+		}
+	}
+	return defaultSpecInstance
+}
+
+// chooseSpec returns which of the candidate specs, given
+// by their indexes, is recognized by a final state
+func chooseSpec(specs []TokenSpec, candidates []int) TokenSpec {
+	chosen := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if specs[candidate].Priority > specs[chosen].Priority ||
+			specs[candidate].Priority == specs[chosen].Priority && candidate < chosen {
+			chosen = candidate
+		}
+	}
+
+	spec := specs[chosen]
+	if spec.DataType == "" {
+		spec.DataType = NULL
+	}
+	return spec
+}
+
+// GetDft returns a copy of the automaton,
+// placed on its initial state
+func (c *CompiledSpec) GetDft() Dft {
+	dft := *c.dft
+	dft.Reset()
+	return dft
+}
+
+// GetTokenSpec returns the token spec recognized by state,
+// and false if state is not a final state
+func (c *CompiledSpec) GetTokenSpec(state State) (TokenSpec, bool) {
+	spec, found := c.accepts[state]
+	return spec, found
+}
+
+// Terminals returns how the grammar names the tokens that
+// reach the parser: the classes of the spec, lowercased,
+// followed by the reserved words. Ignored tokens and
+// comments are left out
+func (c *CompiledSpec) Terminals() []string {
+	terminals := []string{}
+	seen := map[string]bool{}
+	for _, spec := range c.specs {
+		if spec.Ignore || spec.Class == COMMENT {
+			continue
+		}
+		terminal := NewToken(spec.Class, "", NULL).GetClass()
+		if !seen[terminal] {
+			seen[terminal] = true
+			terminals = append(terminals, terminal)
+		}
+	}
+	return append(terminals, ReservedWords()...)
+}
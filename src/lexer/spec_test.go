@@ -0,0 +1,108 @@
+package lexer
+
+import (
+	errorhandling "mgol-go/src/error_handling"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileSpecPriority(t *testing.T) {
+	testCases := []struct {
+		name          string
+		specs         []TokenSpec
+		text          string
+		expectedClass TokenClass
+	}{
+		{
+			name: "First spec wins on ties",
+			specs: []TokenSpec{
+				{Class: "se", Regex: "se"},
+				{Class: IDENTIFIER, Regex: "[a-z]+"},
+			},
+			text:          "se",
+			expectedClass: "se",
+		},
+		{
+			name: "Highest priority wins",
+			specs: []TokenSpec{
+				{Class: IDENTIFIER, Regex: "[a-z]+"},
+				{Class: "se", Regex: "se", Priority: 1},
+			},
+			text:          "se",
+			expectedClass: "se",
+		},
+		{
+			name: "Longer lexem is not affected by priority",
+			specs: []TokenSpec{
+				{Class: IDENTIFIER, Regex: "[a-z]+"},
+				{Class: "se", Regex: "se", Priority: 1},
+			},
+			text:          "sem",
+			expectedClass: IDENTIFIER,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			compiled, err := CompileSpec(tc.specs)
+			r.NoError(err)
+
+			dft := compiled.GetDft()
+			for _, char := range tc.text {
+				_, err := dft.Next(Symbol(char))
+				r.NoError(err)
+			}
+
+			spec, found := compiled.GetTokenSpec(dft.GetCurrentState())
+			r.True(found)
+			r.Equal(tc.expectedClass, spec.Class)
+			r.Equal(NULL, spec.DataType)
+		})
+	}
+}
+
+func TestCompileSpecErrors(t *testing.T) {
+	_, err := CompileSpec(nil)
+	require.ErrorIs(t, err, ErrorEmptySpec)
+
+	_, err = CompileSpec([]TokenSpec{{Class: NUM, Regex: "[0-9"}})
+	require.ErrorIs(t, err, ErrorInvalidRegex)
+}
+
+func TestScanWithCustomSpec(t *testing.T) {
+	specs, err := LoadLexerSpec(strings.NewReader(`[
+		{"class": "id", "regex": "[a-z]+"},
+		{"class": "Num", "regex": "[0-9]+", "type": "inteiro"},
+		{"class": "OPM", "regex": "\\+"},
+		{"class": "WS", "regex": "[ \\n]+", "ignore": true}
+	]`))
+	require.NoError(t, err)
+
+	compiled, err := CompileSpec(specs)
+	require.NoError(t, err)
+
+	symbolTable := GetSymbolTableInstance()
+	defer symbolTable.Cleanup()
+
+	scanner := NewScannerWithSpec(strings.NewReader("ab + 12\n"), symbolTable, errorhandling.NewCollector(), compiled)
+	expectedTokens := []Token{
+		NewToken(IDENTIFIER, "ab", NULL),
+		NewToken(ARIT_OP, "+", NULL),
+		NewToken(NUM, "12", INTEGER),
+		EOF_TOKEN,
+	}
+	for _, expectedToken := range expectedTokens {
+		require.Equal(t, expectedToken, withoutSpan(scanner.Scan()))
+	}
+}
+
+func TestSpecTerminals(t *testing.T) {
+	r := require.New(t)
+
+	expected := append([]string{"id", "num", "lit", "opr", "rcb", "opm", "opmul", "ab_p", "fc_p", "ab_c", "fc_c", "pt_v", "vir"}, ReservedWords()...)
+	r.Equal(expected, GetDefaultSpec().Terminals())
+}
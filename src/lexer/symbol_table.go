@@ -2,6 +2,7 @@ package lexer
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/pterm/pterm"
 )
@@ -56,6 +57,19 @@ func (s *SymbolTable) Update(id string, newToken Token) error {
 	return nil
 }
 
+// GetDeclaredIdentifiers returns, sorted, the
+// identifiers that already have a type
+func (s *SymbolTable) GetDeclaredIdentifiers() []string {
+	identifiers := []string{}
+	for lexem, token := range s.table {
+		if token.IsClass(IDENTIFIER) && token.GetType() != NULL {
+			identifiers = append(identifiers, lexem)
+		}
+	}
+	sort.Strings(identifiers)
+	return identifiers
+}
+
 func (s *SymbolTable) Cleanup() {
 	for k := range s.table {
 		delete(s.table, k)
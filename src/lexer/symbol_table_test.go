@@ -208,3 +208,14 @@ func TestUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDeclaredIdentifiers(t *testing.T) {
+	table := GetSymbolTableInstance()
+	FillSymbolTable(table)
+	table.Insert("media", NewToken(IDENTIFIER, "media", REAL))
+	table.Insert("contador", NewToken(IDENTIFIER, "contador", INTEGER))
+	table.Insert("escrva", NewToken(IDENTIFIER, "escrva", NULL))
+
+	require.Equal(t, []string{"contador", "media"}, table.GetDeclaredIdentifiers())
+	table.Cleanup()
+}
@@ -2,6 +2,7 @@ package lexer
 
 import (
 	"fmt"
+	"mgol-go/src/source"
 	"strings"
 )
 
@@ -15,11 +16,15 @@ const (
 	COMMENT       TokenClass = "Comentário"
 	REL_OP        TokenClass = "OPR"
 	ARIT_OP       TokenClass = "OPM"
+	MUL_OP        TokenClass = "OPMUL"
 	EOF           TokenClass = "EOF"
 	ATTR          TokenClass = "RCB"
 	OPEN_PAR      TokenClass = "AB_P"
 	CLOSE_PAR     TokenClass = "FC_P"
+	OPEN_BRACKET  TokenClass = "AB_C"
+	CLOSE_BRACKET TokenClass = "FC_C"
 	SEMICOLON     TokenClass = "PT_V"
+	COMMA         TokenClass = "VIR"
 	ERROR         TokenClass = "ERRO"
 )
 
@@ -30,6 +35,7 @@ const (
 	INTEGER DataType = "inteiro"
 	REAL    DataType = "real"
 	LITERAL DataType = "literal"
+	BOOLEAN DataType = "logico"
 	NULL    DataType = "NULO"
 )
 
@@ -37,6 +43,7 @@ type Token struct {
 	class    TokenClass
 	lexeme   string
 	dataType DataType
+	span     source.Span
 }
 
 // Constant Tokens
@@ -88,12 +95,26 @@ var LanguageReservedTokens = []Token{
 	NewToken("se", "se", "se"),
 	NewToken("entao", "entao", "entao"),
 	NewToken("fimse", "fimse", "fimse"),
+	NewToken("senao", "senao", "senao"),
 	NewToken("repita", "repita", "repita"),
 	NewToken("fimrepita", "fimrepita", "fimrepita"),
 	NewToken("fim", "fim", "fim"),
 	NewToken("inteiro", "inteiro", "inteiro"),
 	NewToken("literal", "literal", "literal"),
 	NewToken("real", "real", "real"),
+	NewToken("e", "e", "e"),
+	NewToken("ou", "ou", "ou"),
+	NewToken("nao", "nao", "nao"),
+}
+
+// ReservedWords returns the lexems of the
+// LanguageReservedTokens, in the same order
+func ReservedWords() []string {
+	words := []string{}
+	for _, token := range LanguageReservedTokens {
+		words = append(words, token.GetLexem())
+	}
+	return words
 }
 
 func NewToken(class TokenClass, lexeme string, dataType DataType) Token {
@@ -124,6 +145,22 @@ func (t *Token) SetClass(class TokenClass) {
 	t.class = class
 }
 
+// IsClass returns whether t belongs to class, regardless
+// of its lexeme and where it was read from
+func (t Token) IsClass(class TokenClass) bool {
+	return t.class == class
+}
+
+// GetSpan returns the region of the source
+// program the token was read from
+func (t Token) GetSpan() source.Span {
+	return t.span
+}
+
+func (t *Token) SetSpan(span source.Span) {
+	t.span = span
+}
+
 func (t Token) String() string {
 	return fmt.Sprintf("Classe: %v, Lexema: %v, Tipo: %v", t.class, t.lexeme, t.dataType)
 }
@@ -18,8 +18,8 @@ func ContainsSymbol(symbols []Symbol, element Symbol) bool {
 	return false
 }
 
-func ContainsByte(bytes []byte, element byte) bool {
-	for _, e := range bytes {
+func ContainsRune(runes []rune, element rune) bool {
+	for _, e := range runes {
 		if e == element {
 			return true
 		}
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"bytes"
+	"flag"
+	"io"
 	"log"
+	"mgol-go/src/emit"
+	errorhandling "mgol-go/src/error_handling"
 	"mgol-go/src/lexer"
 	"mgol-go/src/parser"
 	"mgol-go/src/stack"
@@ -17,24 +22,154 @@ const (
 	gotoTablePath   = "./src/parser/tables/goto.tsv"
 )
 
+var (
+	diagnosticsFormat = flag.String("diagnostics-format", string(errorhandling.FormatText), "how the errors are written to the standard error: text, json or sarif")
+	languageDir       = flag.String("language-dir", "", "directory with a grammar.json and its tables/action.tsv and tables/goto.tsv to use instead of the embedded ones")
+	emitTree          = flag.String("emit", "", "tree written to the standard output: parse-tree or ast")
+	emitFormat        = flag.String("emit-format", string(emit.FormatDOT), "how the tree of --emit is written: dot or json")
+	traceFormat       = flag.String("trace", "", "write every step of the parser as a table: text, json, markdown or html")
+	traceOutput       = flag.String("trace-output", "", "file the table of --trace is written to, instead of the standard error")
+)
+
 func main() {
-	filePath := os.Args[1]
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case genTablesCommand:
+			genTables(os.Args[2:])
+			return
+		case checkGrammarCommand:
+			checkGrammar(os.Args[2:])
+			return
+		}
+	}
 
-	file, err := os.Open(filePath)
+	flag.Parse()
+	format, err := errorhandling.ParseFormat(*diagnosticsFormat)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer file.Close()
+
+	var kind emit.Kind
+	if *emitTree != "" {
+		kind, err = emit.ParseKind(*emitTree)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	treeFormat, err := emit.ParseFormat(*emitFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var stepsFormat emit.TraceFormat
+	if *traceFormat != "" {
+		stepsFormat, err = emit.ParseTraceFormat(*traceFormat)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var input io.Reader = os.Stdin
+	fileName := "<stdin>"
+	if flag.NArg() > 0 && flag.Arg(0) != "-" {
+		file, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		input = file
+		fileName = flag.Arg(0)
+	}
+
+	// Keep what the scanner reads, so the
+	// diagnostics can show the source lines
+	program := &bytes.Buffer{}
+	source := io.TeeReader(input, program)
 
 	symbolTable := lexer.GetSymbolTableInstance()
 
 	lexer.FillSymbolTable(symbolTable)
 	defer symbolTable.Cleanup()
 
-	scanner := lexer.NewScanner(file, symbolTable)
+	diagnostics := errorhandling.NewCollector()
+	scanner := lexer.NewScanner(source, symbolTable, diagnostics)
 	stack := stack.NewStack(stackCapacity)
-	rules := parser.GetRulesMap(grammarPath)
-	parser := parser.NewParser(scanner, stack, rules, actionTablePath, gotoTablePath)
 
-	parser.Parse()
+	language := parser.GetDefaultLanguage()
+	if *languageDir != "" {
+		language, err = parser.OpenLanguage(os.DirFS(*languageDir))
+		if err != nil {
+			log.Fatalf("Failed to load the grammar: %v", err)
+		}
+	}
+	recorder := parser.NewTraceRecorder()
+	parser := parser.NewParser(scanner, stack, language)
+	if stepsFormat != "" {
+		parser.SetTraceRecorder(recorder)
+	}
+
+	syntaxTree, diagnosticList := parser.Parse()
+	switch format {
+	case errorhandling.FormatJSON:
+		err = errorhandling.WriteJSON(os.Stderr, fileName, diagnosticList)
+	case errorhandling.FormatSARIF:
+		err = errorhandling.WriteSARIF(os.Stderr, fileName, diagnosticList)
+	default:
+		renderer := errorhandling.NewRenderer(os.Stderr, fileName, program.Bytes(), errorhandling.IsTerminal(os.Stderr))
+		renderer.RenderAll(diagnosticList)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if stepsFormat != "" {
+		writeTrace(stepsFormat, recorder.Steps())
+	}
+
+	// Programs with syntax errors have no abstract syntax
+	// tree, and their parse tree shows the error symbol
+	// wherever the parser recovered
+	var tree *emit.Node
+	switch kind {
+	case emit.KindParseTree:
+		if parseTree := parser.ParseTree(); parseTree != nil {
+			tree = emit.FromParseTree(parseTree)
+		}
+	case emit.KindAST:
+		if syntaxTree != nil {
+			tree = emit.FromAST(syntaxTree)
+		}
+	}
+	if tree != nil {
+		if treeFormat == emit.FormatJSON {
+			err = emit.WriteJSON(os.Stdout, tree)
+		} else {
+			err = emit.WriteDOT(os.Stdout, string(kind), tree)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if diagnostics.HasErrors() {
+		os.Exit(1)
+	}
+}
+
+// writeTrace writes the steps of the parser to the
+// file of --trace-output, or to the standard error
+func writeTrace(format emit.TraceFormat, steps []parser.TraceStep) {
+	var output io.Writer = os.Stderr
+	if *traceOutput != "" {
+		file, err := os.Create(*traceOutput)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	if err := emit.WriteTrace(output, format, steps); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -1,11 +1,11 @@
 package parser
 
 import (
-	"encoding/csv"
+	"fmt"
+	"io"
+	"mgol-go/src/grammar"
 	"mgol-go/src/lexer"
-	"os"
 	"strconv"
-	"strings"
 )
 
 type Action int
@@ -17,75 +17,141 @@ const (
 	ERROR
 )
 
+// Terminal identifies a column of the action table
+type Terminal int
+
+// noTerminal is the Terminal of the tokens
+// the action table has no column for
+const noTerminal Terminal = -1
+
+// actionCell is a cell of the action table. The operand is
+// the state of a shift, the rule of a reduce and the error
+// category of an error
+type actionCell struct {
+	action  Action
+	operand int
+}
+
+// ActionReader holds the action table, with a row for
+// each state and a column for each terminal. It is only
+// read after it is built, so many parsers can share it
 type ActionReader struct {
-	records [][]string
-	indexes map[string]int
+	terminals   []string
+	terminalIDs map[string]Terminal
+	cells       []actionCell
 }
 
-func NewActionReader(path string) *ActionReader {
-	file, err := os.Open(path)
+// endOfInput is the column of the action
+// table read on the end of the program
+const endOfInput = "$"
+
+// NewActionReader reads the action table, as tab
+// separated values, from reader. Every cell is checked
+// here, so looking up the table never fails
+func NewActionReader(reader io.Reader) (*ActionReader, error) {
+	header, rows, err := readTable(reader)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("action table: %w", err)
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
-	reader.Comma = '\t'
+	ac := &ActionReader{
+		terminals:   header,
+		terminalIDs: make(map[string]Terminal),
+		cells:       make([]actionCell, 0, len(rows)*len(header)),
+	}
+	for idx, terminal := range header {
+		ac.terminalIDs[terminal] = Terminal(idx)
+	}
+	if _, found := ac.terminalIDs[endOfInput]; !found {
+		return nil, fmt.Errorf("action table: %w %s", ErrorMissingColumn, endOfInput)
+	}
 
-	records, err := reader.ReadAll()
-	if err != nil {
-		panic(err)
+	for state, row := range rows {
+		for column, value := range row {
+			cell, err := parseActionCell(value, len(rows))
+			if err != nil {
+				return nil, fmt.Errorf("action table: state %d reading %s: %w %q", state, header[column], err, value)
+			}
+			ac.cells = append(ac.cells, cell)
+		}
+	}
+	return ac, nil
+}
+
+// parseActionCell reads a cell of an action table with
+// numStates rows, like s12, r3, acc or e2. Empty cells
+// are errors without a category
+func parseActionCell(value string, numStates int) (actionCell, error) {
+	if value == "" {
+		return actionCell{action: ERROR}, nil
+	}
+	if value == "acc" {
+		return actionCell{action: ACCEPT}, nil
+	}
+
+	switch value[0] {
+	case 's':
+		state, err := parseState(value[1:], numStates)
+		return actionCell{action: SHIFT, operand: state}, err
+	case 'r', 'e':
+		operand, err := strconv.Atoi(value[1:])
+		if err != nil || operand < 0 {
+			return actionCell{}, ErrorMalformedCell
+		}
+		if value[0] == 'r' {
+			return actionCell{action: REDUCE, operand: operand}, nil
+		}
+		return actionCell{action: ERROR, operand: operand}, nil
 	}
+	return actionCell{}, ErrorMalformedCell
+}
 
-	ac := &ActionReader{}
-	ac.indexes = make(map[string]int)
-	for idx, record := range records[0] {
-		ac.indexes[record] = idx
+// NumStates returns how many states the table has
+func (a *ActionReader) NumStates() int {
+	if len(a.terminals) == 0 {
+		return 0
 	}
-	ac.records = records
-	return ac
+	return len(a.cells) / len(a.terminals)
 }
 
-func (a *ActionReader) GetAction(state lexer.State, token lexer.Token) (Action, int) {
-	var class string
-	if token == lexer.EOF_TOKEN {
-		class = "$"
-	} else {
+// GetTerminal returns the column of the table read
+// when the parser gets token, or noTerminal if no
+// column is named after its class
+func (a *ActionReader) GetTerminal(token lexer.Token) Terminal {
+	class := endOfInput
+	if !token.IsClass(lexer.EOF) {
 		class = token.GetClass()
 	}
+	if terminal, found := a.terminalIDs[class]; found {
+		return terminal
+	}
+	return noTerminal
+}
 
-	//We need to sum to sum one to access line n because we want to
-	//eliminate the header itself
-	value := []byte(a.records[state+1][a.indexes[class]])
-
-	if len(value) == 0 {
+// Lookup returns the action on state reading terminal,
+// and its operand
+func (a *ActionReader) Lookup(state lexer.State, terminal Terminal) (Action, int) {
+	if terminal == noTerminal {
 		return ERROR, 0
 	}
+	cell := a.cells[int(state)*len(a.terminals)+int(terminal)]
+	return cell.action, cell.operand
+}
 
-	if strings.Compare(string(value), "acc") == 0 {
-		return ACCEPT, 0
-	}
+func (a *ActionReader) GetAction(state lexer.State, token lexer.Token) (Action, int) {
+	return a.Lookup(state, a.GetTerminal(token))
+}
 
-	switch value[0] {
-	case 's':
-		opr, err := strconv.Atoi(string(value[1:]))
-		if err != nil {
-			panic(err)
-		}
-		return SHIFT, opr
-	case 'r':
-		opr, err := strconv.Atoi(string(value[1:]))
-		if err != nil {
-			panic(err)
+// GetExpectedTerminals returns the terminals that don't lead
+// to an error on state, in the order of the table columns.
+// The error symbol is left out, since it is never written
+func (a *ActionReader) GetExpectedTerminals(state lexer.State) []string {
+	expected := []string{}
+	for idx, terminal := range a.terminals {
+		action, _ := a.Lookup(state, Terminal(idx))
+		if action != ERROR && terminal != grammar.ErrorSymbol {
+			expected = append(expected, terminal)
 		}
-		return REDUCE, opr
-	case 'e':
-		errorType, err := strconv.Atoi(string(value[1:]))
-		if err != nil {
-			panic(err)
-		}
-		return ERROR, errorType
 	}
-
-	return -1, -1
+	return expected
 }
@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"io"
 	"mgol-go/src/lexer"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -11,6 +13,24 @@ const (
 	actionTablePath = "./tables/action.tsv"
 )
 
+// openFile opens path, closing
+// it when the test finishes
+func openFile(t *testing.T, path string) io.Reader {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		file.Close()
+	})
+	return file
+}
+
+// readActionTable reads the action table on path
+func readActionTable(t *testing.T, path string) *ActionReader {
+	actionReader, err := NewActionReader(openFile(t, path))
+	require.NoError(t, err)
+	return actionReader
+}
+
 func TestGetAction(t *testing.T) {
 	testCases := []struct {
 		name            string
@@ -35,21 +55,21 @@ func TestGetAction(t *testing.T) {
 		},
 		{
 			name:            "Get reduce",
-			state:           23,
+			state:           25,
 			tokenClass:      lexer.IDENTIFIER,
 			expectedAction:  REDUCE,
 			expectedOperand: 8,
 		},
 		{
 			name:            "Get error",
-			state:           3,
+			state:           22,
 			tokenClass:      "varinicio",
 			expectedAction:  ERROR,
 			expectedOperand: 2,
 		},
 	}
 
-	action := NewActionReader(actionTablePath)
+	action := readActionTable(t, actionTablePath)
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -60,3 +80,36 @@ func TestGetAction(t *testing.T) {
 		})
 	}
 }
+
+func TestGetExpectedTerminals(t *testing.T) {
+	testCases := []struct {
+		name              string
+		state             int
+		expectedTerminals []string
+	}{
+		{
+			name:              "Start of the program",
+			state:             0,
+			expectedTerminals: []string{"inicio"},
+		},
+		{
+			name:              "Start of the commands",
+			state:             3,
+			expectedTerminals: []string{"id", "leia", "escreva", "se", "repita", "fim"},
+		},
+		{
+			name:              "Accepting state",
+			state:             1,
+			expectedTerminals: []string{"$"},
+		},
+	}
+
+	action := readActionTable(t, actionTablePath)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			r.Equal(tc.expectedTerminals, action.GetExpectedTerminals(lexer.State(tc.state)))
+		})
+	}
+}
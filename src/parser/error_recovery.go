@@ -11,12 +11,75 @@ const (
 	recoveryFail   RecoveryStatus = false
 )
 
-func panicMode(parser *Parser, firstToken lexer.Token) RecoveryStatus {
+// errorRuleRecovery recovers like yacc, with the rules of
+// the grammar that use the error symbol. States are popped
+// until one of them shifts error, then error is shifted and
+// the input is skipped up to a token the new state has an
+// action for. When no state shifts error, or the input ends
+// first, the parser is left as it was and false is returned
+func errorRuleRecovery(parser *Parser, firstToken lexer.Token) (lexer.Token, bool) {
+	actionReader := parser.language.Action
+	errorToken := placeholder(firstToken.GetSpan())
+	errorTerminal := actionReader.GetTerminal(errorToken)
+	if errorTerminal == noTerminal {
+		return firstToken, false
+	}
+
+	saved := parser.saveStacks()
+
+	for {
+		topStack, err := parser.stack.Get()
+		if err != nil {
+			parser.restoreStacks(saved)
+			return firstToken, false
+		}
+		action, opr := actionReader.Lookup(lexer.State(topStack.(int)), errorTerminal)
+		if action == SHIFT {
+			parser.record(TraceRecovery, firstToken, "empilha error e vai para %d", opr)
+			parser.pushSymbol(opr, errorToken)
+			break
+		}
+		parser.recordPop(firstToken)
+		parser.popSymbol()
+	}
 
-	stack_copy := parser.stack.Clone()
-	actionReader := NewActionReader(parser.actionTablePath)
+	topStack, _ := parser.stack.Get()
+	state := lexer.State(topStack.(int))
 	token := firstToken
-	parser.stack.Pop()
+	skipped := []lexer.Token{}
+	for {
+		if action, _ := actionReader.Lookup(state, actionReader.GetTerminal(token)); action != ERROR {
+			return token, true
+		}
+		if token.IsClass(lexer.EOF) {
+			parser.restoreStacks(saved)
+			parser.pending = append(skipped, parser.pending...)
+			parser.record(TraceRecovery, firstToken, "restaura a pilha, não há como seguir depois de error")
+			return firstToken, false
+		}
+		parser.record(TraceRecovery, token, "descarta %s", describeToken(token))
+		token = parser.next()
+		skipped = append(skipped, token)
+	}
+}
+
+// panicMode pops states until one of them has an action
+// for the token, discarding the tokens of the input when
+// none has. It returns the token the parser goes on with.
+// The error rules of the grammar, when it has them, are
+// tried first
+func panicMode(parser *Parser, firstToken lexer.Token) (lexer.Token, RecoveryStatus) {
+	if token, recovered := errorRuleRecovery(parser, firstToken); recovered {
+		return token, recoverySucess
+	}
+	parser.reportPendingError(nil)
+
+	saved := parser.saveStacks()
+	actionReader := parser.language.Action
+	token := firstToken
+	terminal := actionReader.GetTerminal(token)
+	parser.recordPop(token)
+	parser.popSymbol()
 
 	for {
 		for parser.stack.GetLength() > 0 {
@@ -26,18 +89,30 @@ func panicMode(parser *Parser, firstToken lexer.Token) RecoveryStatus {
 			}
 
 			state := lexer.State(topStack.(int))
-			action, _ := actionReader.GetAction(state, token)
+			action, _ := actionReader.Lookup(state, terminal)
 
 			if action != ERROR {
-				return recoverySucess
+				return token, recoverySucess
 			}
-			parser.stack.Pop()
+			parser.recordPop(token)
+			parser.popSymbol()
 		}
 
-		parser.stack = stack_copy
-		token, _, _ = parser.scanner.Scan()
-		if token == lexer.EOF_TOKEN {
-			return recoveryFail
+		parser.restoreStacks(saved)
+		parser.record(TraceRecovery, token, "restaura a pilha e descarta %s", describeToken(token))
+		token = parser.next()
+		if token.IsClass(lexer.EOF) {
+			parser.record(TraceError, token, "fim da entrada durante a recuperação")
+			return token, recoveryFail
 		}
+		terminal = actionReader.GetTerminal(token)
+	}
+}
+
+// recordPop records that the recovery pops the
+// state on top of the stack, on the token lookahead
+func (p *Parser) recordPop(lookahead lexer.Token) {
+	if topStack, err := p.stack.Get(); err == nil {
+		p.record(TraceRecovery, lookahead, "desempilha %d", topStack)
 	}
 }
@@ -0,0 +1,112 @@
+package parser
+
+import (
+	errorhandling "mgol-go/src/error_handling"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveryWithoutRepair(t *testing.T) {
+	testCases := []struct {
+		name            string
+		program         string
+		expectedCode    errorhandling.Code
+		expectedMessage string
+	}{
+		{
+			name:            "Error rule of commands",
+			program:         "inicio varinicio inteiro A; varfim; A <- ; leia A; fim",
+			expectedCode:    "P011",
+			expectedMessage: "comando inválido, encontrado ';', esperado um de: identificador, número, operador aritmético, '('",
+		},
+		{
+			name:            "Error rule of commands on a read",
+			program:         "inicio varinicio inteiro A; varfim; leia ; leia A; fim",
+			expectedCode:    "P011",
+			expectedMessage: "comando inválido, encontrado ';', esperado identificador",
+		},
+		{
+			name:            "Error rule of commands on a write",
+			program:         "inicio varinicio inteiro A; varfim; escreva ; leia A; fim",
+			expectedCode:    "P011",
+			expectedMessage: "comando inválido, encontrado ';', esperado um de: identificador, literal entre aspas, número",
+		},
+		{
+			name:            "Error rule of declarations",
+			program:         "inicio varinicio inteiro A B C D; varfim; fim",
+			expectedCode:    "P010",
+			expectedMessage: "declaração de variável inválida, encontrado 'B', esperado um de: ';', '<-', ',', '['",
+		},
+		{
+			name:            "Panic mode",
+			program:         "inicio varinicio inteiro A; varfim; A <- fim",
+			expectedCode:    "P006",
+			expectedMessage: "tentativa de declaração inválida, encontrado 'fim', esperado um de: identificador, número, operador aritmético, '('",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			_, diagnostics := parseProgram(t, tc.program)
+			r.Len(diagnostics, 1)
+			r.Equal(tc.expectedCode, diagnostics[0].Code)
+			r.Equal(tc.expectedMessage, diagnostics[0].Message)
+		})
+	}
+}
+
+func TestRecoveryKeepsTheSemanticStack(t *testing.T) {
+	testCases := []struct {
+		name             string
+		program          string
+		expectedMessages []string
+	}{
+		{
+			name:    "Semantic error after an error rule",
+			program: "inicio varinicio inteiro A; real B; varfim; A <- ; A <- B; fim",
+			expectedMessages: []string{
+				"comando inválido, encontrado ';', esperado um de: identificador, número, operador aritmético, '('",
+				"tipos diferentes para a atribuição, 'A' é do tipo 'inteiro', enquanto que 'B' é do tipo 'real'",
+			},
+		},
+		{
+			name:    "Semantic error after a repair",
+			program: "inicio varinicio inteiro A real C; inteiro D; varfim; D <- C; fim",
+			expectedMessages: []string{
+				"declaração de variáveis mal formada, inserido ';' depois de 'A'",
+				"tipos diferentes para a atribuição, 'D' é do tipo 'inteiro', enquanto que 'C' é do tipo 'real'",
+			},
+		},
+		{
+			name:    "Undeclared operand",
+			program: "inicio varinicio inteiro A; real B; varfim; A <- X + 1; se (A > B) entao fimse fim",
+			expectedMessages: []string{
+				"variável 'X' não declarada",
+				"operandos com tipos incompatíveis, 'A' é do tipo 'inteiro', enquanto que 'B' é do tipo 'real'",
+			},
+		},
+		{
+			name:    "Undeclared operand of a condition",
+			program: "inicio varinicio inteiro A; real B; varfim; leia X; se (Y > A) entao A <- B; fimse fim",
+			expectedMessages: []string{
+				"variável 'X' não declarada",
+				"variável 'Y' não declarada",
+				"tipos diferentes para a atribuição, 'A' é do tipo 'inteiro', enquanto que 'B' é do tipo 'real'",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			_, diagnostics := parseProgram(t, tc.program)
+			messages := []string{}
+			for _, diagnostic := range diagnostics {
+				messages = append(messages, diagnostic.Message)
+			}
+			r.Equal(tc.expectedMessages, messages)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"fmt"
+	errorhandling "mgol-go/src/error_handling"
+	"mgol-go/src/lexer"
+	"strings"
+)
+
+// terminalSpelling tells how the terminals of the grammar
+// whose names aren't what the student writes appear on the
+// source program. Reserved words are written as they are
+var terminalSpelling = map[string]string{
+	"pt_v":     "';'",
+	"vir":      "','",
+	"rcb":      "'<-'",
+	"ab_p":     "'('",
+	"fc_p":     "')'",
+	"ab_c":     "'['",
+	"fc_c":     "']'",
+	"opm":      "operador aritmético",
+	"opmul":    "operador multiplicativo",
+	"opr":      "operador relacional",
+	"id":       "identificador",
+	"num":      "número",
+	"lit":      "literal entre aspas",
+	endOfInput: "fim do arquivo",
+}
+
+// describeTerminal returns how terminal is
+// written on the source program
+func describeTerminal(terminal string) string {
+	if spelling, found := terminalSpelling[terminal]; found {
+		return spelling
+	}
+	return fmt.Sprintf("'%s'", terminal)
+}
+
+// describeToken returns what was found on the source
+// program when the parser got token
+func describeToken(token lexer.Token) string {
+	if token.IsClass(lexer.EOF) {
+		return describeTerminal(endOfInput)
+	}
+	return fmt.Sprintf("'%s'", token.GetLexem())
+}
+
+// terminalToken returns a token of the class of terminal,
+// for the parser to try it on the tables
+func terminalToken(terminal string) lexer.Token {
+	if terminal == endOfInput {
+		return lexer.EOF_TOKEN
+	}
+	return lexer.NewToken(lexer.TokenClass(terminal), terminal, lexer.NULL)
+}
+
+// expectedTerminals returns the terminals the parser, with
+// states on its stack, shifts next. The LALR tables merge
+// the lookaheads of similar states, so some terminals that
+// the top state reduces on fail once the reductions are
+// done, and those are left out
+func (p *Parser) expectedTerminals(states []int) []string {
+	expected := []string{}
+	for _, terminal := range p.language.Action.GetExpectedTerminals(lexer.State(states[len(states)-1])) {
+		if p.simulate(states, []lexer.Token{terminalToken(terminal)}) == 1 {
+			expected = append(expected, terminal)
+		}
+	}
+	return expected
+}
+
+// expectedMessage tells that token was found
+// where one of the expected terminals should be
+func expectedMessage(token lexer.Token, expected []string) string {
+	found := fmt.Sprintf("encontrado %s", describeToken(token))
+	if len(expected) == 0 {
+		return found
+	}
+
+	descriptions := []string{}
+	for _, terminal := range expected {
+		descriptions = append(descriptions, describeTerminal(terminal))
+	}
+	if len(descriptions) == 1 {
+		return fmt.Sprintf("%s, esperado %s", found, descriptions[0])
+	}
+	return fmt.Sprintf("%s, esperado um de: %s", found, strings.Join(descriptions, ", "))
+}
+
+// withKeywordSuggestion adds to diagnostic a hint with the
+// reserved word the student may have misspelled, either on
+// token or on the identifier read right before it, which the
+// parser took as a variable. Declared variables are never
+// taken as typos
+func withKeywordSuggestion(diagnostic errorhandling.Diagnostic, token, previous lexer.Token) errorhandling.Diagnostic {
+	for _, candidate := range []lexer.Token{token, previous} {
+		if keyword, found := misspelledKeyword(candidate); found {
+			return diagnostic.WithHint("você quis dizer '%s' em vez de '%s'?", keyword, candidate.GetLexem())
+		}
+	}
+	return diagnostic
+}
+
+// misspelledKeyword returns the reserved word token may
+// be a typo of, when it is an undeclared identifier
+func misspelledKeyword(token lexer.Token) (string, bool) {
+	if !token.IsClass(lexer.IDENTIFIER) || token.GetType() != lexer.NULL {
+		return "", false
+	}
+	return errorhandling.Suggest(token.GetLexem(), lexer.ReservedWords())
+}
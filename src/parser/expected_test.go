@@ -0,0 +1,120 @@
+package parser
+
+import (
+	errorhandling "mgol-go/src/error_handling"
+	"mgol-go/src/lexer"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectedMessage(t *testing.T) {
+	testCases := []struct {
+		name            string
+		token           lexer.Token
+		expected        []string
+		expectedMessage string
+	}{
+		{
+			name:            "Single terminal",
+			token:           lexer.NewToken(lexer.IDENTIFIER, "C", lexer.NULL),
+			expected:        []string{"pt_v"},
+			expectedMessage: "encontrado 'C', esperado ';'",
+		},
+		{
+			name:            "Many terminals",
+			token:           lexer.NewToken(lexer.SEMICOLON, ";", lexer.NULL),
+			expected:        []string{"id", "rcb", "ab_p", "fim"},
+			expectedMessage: "encontrado ';', esperado um de: identificador, '<-', '(', 'fim'",
+		},
+		{
+			name:            "Operators",
+			token:           lexer.NewToken(lexer.IDENTIFIER, "C", lexer.NULL),
+			expected:        []string{"opm", "opmul", "opr", "pt_v"},
+			expectedMessage: "encontrado 'C', esperado um de: operador aritmético, operador multiplicativo, operador relacional, ';'",
+		},
+		{
+			name:            "End of file",
+			token:           lexer.NewToken(lexer.EOF, "EOF", lexer.NULL),
+			expected:        []string{"fim"},
+			expectedMessage: "encontrado fim do arquivo, esperado 'fim'",
+		},
+		{
+			name:            "Nothing expected",
+			token:           lexer.NewToken(lexer.IDENTIFIER, "C", lexer.NULL),
+			expected:        []string{},
+			expectedMessage: "encontrado 'C'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			r.Equal(tc.expectedMessage, expectedMessage(tc.token, tc.expected))
+		})
+	}
+}
+
+func TestExpectedTerminalsAfterReductions(t *testing.T) {
+	r := require.New(t)
+	program, diagnostics := parseProgram(t, "inicio varinicio inteiro A, B; varfim; A <- 1 B <- 2; fim")
+	r.Nil(program)
+	r.Len(diagnostics, 1)
+	r.Equal([]string{"encontrado 'B', esperado um de: ';', operador aritmético, operador multiplicativo"}, diagnostics[0].Notes)
+}
+
+func TestEveryTerminalIsSpelled(t *testing.T) {
+	r := require.New(t)
+	reserved := map[string]bool{}
+	for _, word := range lexer.ReservedWords() {
+		reserved[word] = true
+	}
+
+	for _, terminal := range lexer.GetDefaultSpec().Terminals() {
+		if !reserved[terminal] {
+			r.Contains(terminalSpelling, terminal)
+		}
+	}
+}
+
+func TestWithKeywordSuggestion(t *testing.T) {
+	testCases := []struct {
+		name         string
+		token        lexer.Token
+		previous     lexer.Token
+		expectedHint string
+	}{
+		{
+			name:         "Misspelled keyword found",
+			token:        lexer.NewToken(lexer.IDENTIFIER, "fimsee", lexer.NULL),
+			previous:     lexer.NewToken(lexer.SEMICOLON, ";", lexer.NULL),
+			expectedHint: "você quis dizer 'fimse' em vez de 'fimsee'?",
+		},
+		{
+			name:         "Misspelled keyword before the error",
+			token:        lexer.NewToken(lexer.IDENTIFIER, "x", lexer.NULL),
+			previous:     lexer.NewToken(lexer.IDENTIFIER, "escrva", lexer.NULL),
+			expectedHint: "você quis dizer 'escreva' em vez de 'escrva'?",
+		},
+		{
+			name:         "Declared variables are not typos",
+			token:        lexer.NewToken(lexer.SEMICOLON, ";", lexer.NULL),
+			previous:     lexer.NewToken(lexer.IDENTIFIER, "fima", lexer.INTEGER),
+			expectedHint: "",
+		},
+		{
+			name:         "Nothing close",
+			token:        lexer.NewToken(lexer.IDENTIFIER, "resultado", lexer.NULL),
+			previous:     lexer.NewToken(lexer.SEMICOLON, ";", lexer.NULL),
+			expectedHint: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			diagnostic := withKeywordSuggestion(errorhandling.NewDiagnostic(errorhandling.SyntaxErrorCode(1), tc.token.GetSpan(), "token inesperado"), tc.token, tc.previous)
+			r.Equal(tc.expectedHint, diagnostic.Hint)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package parser
+
+import (
+	"io"
+	errorhandling "mgol-go/src/error_handling"
+	"mgol-go/src/grammar"
+	"mgol-go/src/lexer"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	grammarPath = "./grammar.json"
+)
+
+// loadGrammarRules reads the rules of grammar.json
+func loadGrammarRules(t *testing.T) []Rule {
+	rules, err := grammar.LoadRules(openFile(t, grammarPath))
+	require.NoError(t, err)
+	return rules
+}
+
+// generateTables writes the tables generated
+// from grammar.json to dir and returns their paths
+func generateTables(t *testing.T, dir string) (string, string) {
+	r := require.New(t)
+
+	tables, err := grammar.GenerateTables(loadGrammarRules(t), grammar.LALR, ErrorCategories)
+	r.NoError(err)
+	r.Empty(tables.Conflicts)
+
+	actionPath := filepath.Join(dir, "action.tsv")
+	gotoPath := filepath.Join(dir, "goto.tsv")
+	for path, write := range map[string]func(io.Writer) error{
+		actionPath: tables.WriteAction,
+		gotoPath:   tables.WriteGoto,
+	} {
+		file, err := os.Create(path)
+		r.NoError(err)
+		r.NoError(write(file))
+		r.NoError(file.Close())
+	}
+	return actionPath, gotoPath
+}
+
+// recognize drives an LR parser over program with the given
+// tables, returning the rules it reduced and whether the
+// program was accepted. It stops on the first syntax error
+func recognize(actionReader *ActionReader, gotoReader *GotoReader, rules *RulesMap, program string) ([]int, bool) {
+	symbolTable := lexer.GetSymbolTableInstance()
+	lexer.FillSymbolTable(symbolTable)
+	defer symbolTable.Cleanup()
+
+	scanner := lexer.NewScanner(strings.NewReader(program), symbolTable, errorhandling.NewCollector())
+	next := func() lexer.Token {
+		token := scanner.Scan()
+		for isInTokensToIgnore(token) {
+			token = scanner.Scan()
+		}
+		return token
+	}
+
+	states := []int{0}
+	reduced := []int{}
+	token := next()
+	for {
+		action, opr := actionReader.GetAction(lexer.State(states[len(states)-1]), token)
+		switch action {
+		case SHIFT:
+			states = append(states, opr)
+			token = next()
+		case REDUCE:
+			rule := rules.GetRule(opr)
+			states = states[:len(states)-len(rule.Right)]
+			states = append(states, gotoReader.GetGoto(lexer.State(states[len(states)-1]), rule.Left))
+			reduced = append(reduced, opr)
+		case ACCEPT:
+			return reduced, true
+		default:
+			return reduced, false
+		}
+	}
+}
+
+func TestGeneratedTablesMatchTheShippedOnes(t *testing.T) {
+	r := require.New(t)
+	actionPath, gotoPath := generateTables(t, t.TempDir())
+
+	shippedGoto := readGotoTable(t, gotoTablePath)
+	generatedGoto := readGotoTable(t, gotoPath)
+	r.Equal(shippedGoto.nonterminals, generatedGoto.nonterminals)
+	r.Equal(shippedGoto.states, generatedGoto.states)
+
+	shippedAction := readActionTable(t, actionTablePath)
+	generatedAction := readActionTable(t, actionPath)
+	r.Equal(shippedAction.terminals, generatedAction.terminals)
+	r.Equal(shippedAction.NumStates(), generatedAction.NumStates())
+
+	// Error cells may have other categories, every
+	// other cell must be the same
+	for idx, cell := range shippedAction.cells {
+		generated := generatedAction.cells[idx]
+		state, terminal := idx/len(shippedAction.terminals), shippedAction.terminals[idx%len(shippedAction.terminals)]
+		if cell.action == ERROR {
+			r.Equal(ERROR, generated.action, "state %d, %s", state, terminal)
+			continue
+		}
+		r.Equal(cell, generated, "state %d, %s", state, terminal)
+	}
+}
+
+func TestGeneratedTablesParseTheSamePrograms(t *testing.T) {
+	actionPath, gotoPath := generateTables(t, t.TempDir())
+	rules := createMapFromSlice(loadGrammarRules(t))
+
+	shippedAction, shippedGoto := readActionTable(t, actionTablePath), readGotoTable(t, gotoTablePath)
+	generatedAction, generatedGoto := readActionTable(t, actionPath), readGotoTable(t, gotoPath)
+
+	testCases := []struct {
+		name     string
+		program  string
+		accepted bool
+	}{
+		{
+			name: "Every construction",
+			program: `inicio
+varinicio
+literal A;
+inteiro B;
+real C;
+varfim;
+escreva "Digite B";
+leia B;
+se(B>2)
+entao
+se(B<=4)
+entao
+escreva "B esta entre 2 e 4";
+fimse
+fimse
+B<-B+1;
+C<-5.0;
+repita(B<5)
+C<-C+2.0;
+escreva C;
+B<-B+1;
+fimrepita
+escreva A;
+fim`,
+			accepted: true,
+		},
+		{
+			name:     "Empty program",
+			program:  "inicio varinicio varfim; fim",
+			accepted: true,
+		},
+		{
+			name:     "Missing semicolon",
+			program:  "inicio varinicio inteiro B; varfim; B<-B+1 escreva B; fim",
+			accepted: false,
+		},
+		{
+			name:     "Declaration out of place",
+			program:  "inicio varinicio varfim; inteiro B; fim",
+			accepted: false,
+		},
+		{
+			name:     "Unbalanced parentheses",
+			program:  "inicio varinicio varfim; se(B>2 entao fimse fim",
+			accepted: false,
+		},
+		{
+			name:     "Missing end",
+			program:  "inicio varinicio varfim; escreva 1;",
+			accepted: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			expectedReductions, accepted := recognize(shippedAction, shippedGoto, rules, tc.program)
+			r.Equal(tc.accepted, accepted)
+
+			reductions, accepted := recognize(generatedAction, generatedGoto, rules, tc.program)
+			r.Equal(tc.accepted, accepted)
+			r.Equal(expectedReductions, reductions)
+		})
+	}
+}
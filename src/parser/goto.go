@@ -1,54 +1,83 @@
 package parser
 
 import (
-	"encoding/csv"
+	"fmt"
+	"io"
 	"mgol-go/src/lexer"
-	"os"
-	"strconv"
 )
 
+// Nonterminal identifies a column of the goto table
+type Nonterminal int
+
+// GotoReader holds the goto table, with a row for each
+// state and a column for each nonterminal. It is only
+// read after it is built, so many parsers can share it
 type GotoReader struct {
-	records [][]string
-	indexes map[string]int
+	nonterminals   []string
+	nonterminalIDs map[string]Nonterminal
+	states         []int
 }
 
-func NewGotoReader(path string) *GotoReader {
-	gotoCsvFile, err := os.Open(path)
+// NewGotoReader reads the goto table, as tab separated
+// values, from reader. Every cell is checked here, so
+// looking up the table never fails
+func NewGotoReader(reader io.Reader) (*GotoReader, error) {
+	header, rows, err := readTable(reader)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("goto table: %w", err)
 	}
-	defer gotoCsvFile.Close()
 
-	gotoCsv := csv.NewReader(gotoCsvFile)
-	gotoCsv.Comma = '\t'
+	got := &GotoReader{
+		nonterminals:   header,
+		nonterminalIDs: make(map[string]Nonterminal),
+		states:         make([]int, 0, len(rows)*len(header)),
+	}
+	for idx, nonTerminal := range header {
+		got.nonterminalIDs[nonTerminal] = Nonterminal(idx)
+	}
 
-	records, err := gotoCsv.ReadAll()
-	if err != nil {
-		panic(err)
+	for state, row := range rows {
+		for column, value := range row {
+			if value == "" {
+				got.states = append(got.states, -1)
+				continue
+			}
+			next, err := parseState(value, len(rows))
+			if err != nil {
+				return nil, fmt.Errorf("goto table: state %d on %s: %w %q", state, header[column], err, value)
+			}
+			got.states = append(got.states, next)
+		}
 	}
+	return got, nil
+}
 
-	got := &GotoReader{}
-	got.indexes = make(map[string]int)
-	for idx, record := range records[0] {
-		got.indexes[record] = idx
+// NumStates returns how many states the table has
+func (g *GotoReader) NumStates() int {
+	if len(g.nonterminals) == 0 {
+		return 0
 	}
-	got.records = records
-	return got
+	return len(g.states) / len(g.nonterminals)
 }
 
-func (g *GotoReader) GetGoto(state lexer.State, nonTerminal string) int {
-	//We need to sum to sum one to access line n because we want to
-	//eliminate the header itself
-	value := g.records[state+1][g.indexes[nonTerminal]]
+// GetNonterminal returns the column of nonTerminal
+// and false if the table has no such column
+func (g *GotoReader) GetNonterminal(nonTerminal string) (Nonterminal, bool) {
+	id, found := g.nonterminalIDs[nonTerminal]
+	return id, found
+}
 
-	if len(value) == 0 {
-		return -1
-	}
+// Lookup returns the state the parser goes to from
+// state after reducing to nonTerminal, or -1 if the
+// cell is empty
+func (g *GotoReader) Lookup(state lexer.State, nonTerminal Nonterminal) int {
+	return g.states[int(state)*len(g.nonterminals)+int(nonTerminal)]
+}
 
-	ret_state, err := strconv.Atoi(string(value))
-	if err != nil {
-		panic(err)
+func (g *GotoReader) GetGoto(state lexer.State, nonTerminal string) int {
+	id, found := g.GetNonterminal(nonTerminal)
+	if !found {
+		return -1
 	}
-
-	return ret_state
+	return g.Lookup(state, id)
 }
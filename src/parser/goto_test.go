@@ -26,9 +26,9 @@ func TestGetGoto(t *testing.T) {
 		},
 		{
 			name:          "Getting Valid State 2",
-			inicialState:  21,
+			inicialState:  22,
 			nonTerminal:   "L",
-			expectedState: 49,
+			expectedState: 55,
 		},
 		{
 			name:          "Getting Non Existent State",
@@ -38,7 +38,7 @@ func TestGetGoto(t *testing.T) {
 		},
 	}
 
-	got := NewGotoReader(gotoTablePath)
+	got := readGotoTable(t, gotoTablePath)
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -48,3 +48,10 @@ func TestGetGoto(t *testing.T) {
 		})
 	}
 }
+
+// readGotoTable reads the goto table on path
+func readGotoTable(t *testing.T, path string) *GotoReader {
+	gotoReader, err := NewGotoReader(openFile(t, path))
+	require.NoError(t, err)
+	return gotoReader
+}
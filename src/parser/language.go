@@ -0,0 +1,212 @@
+package parser
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"mgol-go/src/grammar"
+	"mgol-go/src/lexer"
+	"sort"
+)
+
+var (
+	ErrorUnknownRule     = fmt.Errorf("unknown rule")
+	ErrorTablesMismatch  = fmt.Errorf("the action and goto tables have a different number of states")
+	ErrorUnknownGotoRule = fmt.Errorf("the goto table has no column for the left side of rule")
+	ErrorIncompleteRule  = fmt.Errorf("error rules need a message and a code")
+	ErrorMissingGoto     = fmt.Errorf("no state to go to after reducing by rule")
+)
+
+// Where the grammar and its tables are
+// found on the files of a language
+const (
+	GrammarFile     = "grammar.json"
+	ActionTableFile = "tables/action.tsv"
+	GotoTableFile   = "tables/goto.tsv"
+)
+
+//go:embed grammar.json tables/action.tsv tables/goto.tsv
+var languageFiles embed.FS
+
+var defaultLanguageInstance *Language
+
+// LanguageFiles returns the grammar of mgol and its
+// tables, embedded on the compiler when it is built
+func LanguageFiles() fs.FS {
+	return languageFiles
+}
+
+// Language is what drives the parser: the rules of the
+// grammar and its tables. It is checked when loaded and
+// only read afterwards, so every Parser can share it
+type Language struct {
+	Rules  *RulesMap
+	Action *ActionReader
+	Goto   *GotoReader
+	// lefts holds the goto column of
+	// the left side of each rule
+	lefts map[int]Nonterminal
+}
+
+// OpenLanguage reads the grammar and the tables of a
+// language from files, where they are laid out like
+// on the directory of this package
+func OpenLanguage(files fs.FS) (*Language, error) {
+	grammarFile, err := files.Open(GrammarFile)
+	if err != nil {
+		return nil, err
+	}
+	defer grammarFile.Close()
+
+	rules, err := grammar.LoadRules(grammarFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", GrammarFile, err)
+	}
+
+	actionFile, err := files.Open(ActionTableFile)
+	if err != nil {
+		return nil, err
+	}
+	defer actionFile.Close()
+
+	actionReader, err := NewActionReader(actionFile)
+	if err != nil {
+		return nil, err
+	}
+
+	gotoFile, err := files.Open(GotoTableFile)
+	if err != nil {
+		return nil, err
+	}
+	defer gotoFile.Close()
+
+	gotoReader, err := NewGotoReader(gotoFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLanguage(createMapFromSlice(rules), actionReader, gotoReader)
+}
+
+// NewLanguage checks the tables agree with each other and
+// with rules: they have the same states, every reduce names
+// a rule and every rule has a column on the goto table, with
+// a state for each one the parser may uncover reducing by it.
+// The error rules must tell the message and code of their errors
+func NewLanguage(rules *RulesMap, actionReader *ActionReader, gotoReader *GotoReader) (*Language, error) {
+	if actionReader.NumStates() != gotoReader.NumStates() {
+		return nil, fmt.Errorf("%w: %d and %d", ErrorTablesMismatch, actionReader.NumStates(), gotoReader.NumStates())
+	}
+
+	for _, rule := range *rules {
+		if rule.HasErrorSymbol() && (rule.Message == "" || rule.Code == "") {
+			return nil, fmt.Errorf("%w: %s", ErrorIncompleteRule, rule)
+		}
+	}
+
+	lefts := map[int]Nonterminal{}
+	for number, rule := range *rules {
+		left, found := gotoReader.GetNonterminal(rule.Left)
+		if !found {
+			// The augmented rule is never reduced
+			continue
+		}
+		lefts[number] = left
+	}
+
+	transitions := predecessors(actionReader, gotoReader)
+	for state := 0; state < actionReader.NumStates(); state++ {
+		for idx, terminal := range actionReader.terminals {
+			action, opr := actionReader.Lookup(lexer.State(state), Terminal(idx))
+			if action != REDUCE {
+				continue
+			}
+			if _, found := (*rules)[opr]; !found {
+				return nil, fmt.Errorf("action table: state %d reading %s: %w %d", state, terminal, ErrorUnknownRule, opr)
+			}
+			if _, found := lefts[opr]; !found {
+				return nil, fmt.Errorf("%w %s", ErrorUnknownGotoRule, rules.GetRule(opr))
+			}
+			rule := rules.GetRule(opr)
+			for _, uncovered := range statesBefore(state, rule.Right, transitions) {
+				if gotoReader.Lookup(lexer.State(uncovered), lefts[opr]) < 0 {
+					return nil, fmt.Errorf("goto table: state %d on %s: %w %s", uncovered, rule.Left, ErrorMissingGoto, rule)
+				}
+			}
+		}
+	}
+
+	return &Language{
+		Rules:  rules,
+		Action: actionReader,
+		Goto:   gotoReader,
+		lefts:  lefts,
+	}, nil
+}
+
+// predecessors returns, for each state, the states that
+// lead to it by shifting or going to each symbol
+func predecessors(actionReader *ActionReader, gotoReader *GotoReader) []map[string][]int {
+	transitions := make([]map[string][]int, actionReader.NumStates())
+	for state := range transitions {
+		transitions[state] = map[string][]int{}
+	}
+
+	for state := 0; state < actionReader.NumStates(); state++ {
+		for idx, terminal := range actionReader.terminals {
+			if action, next := actionReader.Lookup(lexer.State(state), Terminal(idx)); action == SHIFT {
+				transitions[next][terminal] = append(transitions[next][terminal], state)
+			}
+		}
+		for idx, nonterminal := range gotoReader.nonterminals {
+			if next := gotoReader.Lookup(lexer.State(state), Nonterminal(idx)); next >= 0 {
+				transitions[next][nonterminal] = append(transitions[next][nonterminal], state)
+			}
+		}
+	}
+	return transitions
+}
+
+// statesBefore returns the states the parser may be on
+// right before reading the symbols that took it to state,
+// which are the ones uncovered when they are reduced
+func statesBefore(state int, symbols []string, transitions []map[string][]int) []int {
+	states := map[int]bool{state: true}
+	for idx := len(symbols) - 1; idx >= 0; idx-- {
+		previous := map[int]bool{}
+		for current := range states {
+			for _, before := range transitions[current][symbols[idx]] {
+				previous[before] = true
+			}
+		}
+		states = previous
+	}
+
+	sorted := []int{}
+	for before := range states {
+		sorted = append(sorted, before)
+	}
+	sort.Ints(sorted)
+	return sorted
+}
+
+// GetDefaultLanguage returns the language of mgol, loaded
+// once from the embedded files and shared afterwards
+func GetDefaultLanguage() *Language {
+	if defaultLanguageInstance == nil {
+		language, err := OpenLanguage(LanguageFiles())
+		if err != nil {
+			log.Fatalf("Failed to load the grammar: %v", err)
+		}
+		defaultLanguageInstance = language
+	}
+	return defaultLanguageInstance
+}
+
+// GotoAfter returns the state the parser goes to from
+// state after reducing rule. NewLanguage makes sure there
+// is one for every state the reduction may uncover
+func (l *Language) GotoAfter(state lexer.State, rule int) int {
+	return l.Goto.Lookup(state, l.lefts[rule])
+}
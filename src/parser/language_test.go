@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"io/fs"
+	"mgol-go/src/lexer"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	tinyGrammar = `[
+		{"rule_number": 0, "left": "S'", "right": ["S"]},
+		{"rule_number": 1, "left": "S", "right": ["a"]}
+	]`
+	tinyActionTable = "estado\ta\t$\r\n0\ts2\te1\r\n1\t\tacc\r\n2\t\tr1\r\n"
+	tinyGotoTable   = "estado\tS\r\n0\t1\r\n1\t\r\n2\t\r\n"
+)
+
+// tinyLanguage returns the files of a language
+// that only accepts a, with some of them replaced
+func tinyLanguage(replaced map[string]string) fstest.MapFS {
+	files := fstest.MapFS{}
+	for name, data := range map[string]string{
+		GrammarFile:     tinyGrammar,
+		ActionTableFile: tinyActionTable,
+		GotoTableFile:   tinyGotoTable,
+	} {
+		if replacement, found := replaced[name]; found {
+			data = replacement
+		}
+		if data != "" {
+			files[name] = &fstest.MapFile{Data: []byte(data)}
+		}
+	}
+	return files
+}
+
+func TestOpenLanguage(t *testing.T) {
+	testCases := []struct {
+		name        string
+		files       fs.FS
+		expectedErr error
+	}{
+		{
+			name:  "Tiny language",
+			files: tinyLanguage(nil),
+		},
+		{
+			name:        "Missing action table",
+			files:       tinyLanguage(map[string]string{ActionTableFile: ""}),
+			expectedErr: fs.ErrNotExist,
+		},
+		{
+			name:        "Missing grammar",
+			files:       tinyLanguage(map[string]string{GrammarFile: ""}),
+			expectedErr: fs.ErrNotExist,
+		},
+		{
+			name:        "Malformed shift",
+			files:       tinyLanguage(map[string]string{ActionTableFile: "estado\ta\t$\r\n0\tsx\t\r\n1\t\tacc\r\n2\t\tr1\r\n"}),
+			expectedErr: ErrorMalformedCell,
+		},
+		{
+			name:        "Unknown action",
+			files:       tinyLanguage(map[string]string{ActionTableFile: "estado\ta\t$\r\n0\tq2\t\r\n1\t\tacc\r\n2\t\tr1\r\n"}),
+			expectedErr: ErrorMalformedCell,
+		},
+		{
+			name:        "Shift to an unknown state",
+			files:       tinyLanguage(map[string]string{ActionTableFile: "estado\ta\t$\r\n0\ts9\t\r\n1\t\tacc\r\n2\t\tr1\r\n"}),
+			expectedErr: ErrorUnknownState,
+		},
+		{
+			name:        "Goto to an unknown state",
+			files:       tinyLanguage(map[string]string{GotoTableFile: "estado\tS\r\n0\t3\r\n1\t\r\n2\t\r\n"}),
+			expectedErr: ErrorUnknownState,
+		},
+		{
+			name:        "Rows out of order",
+			files:       tinyLanguage(map[string]string{GotoTableFile: "estado\tS\r\n0\t1\r\n2\t\r\n1\t\r\n"}),
+			expectedErr: ErrorMalformedRow,
+		},
+		{
+			name:        "No header",
+			files:       tinyLanguage(map[string]string{GotoTableFile: "0\t1\r\n1\t\r\n2\t\r\n"}),
+			expectedErr: ErrorEmptyTable,
+		},
+		{
+			name:        "No end of input column",
+			files:       tinyLanguage(map[string]string{ActionTableFile: "estado\ta\r\n0\ts2\r\n1\t\r\n2\t\r\n"}),
+			expectedErr: ErrorMissingColumn,
+		},
+		{
+			name:        "Reduce by an unknown rule",
+			files:       tinyLanguage(map[string]string{ActionTableFile: "estado\ta\t$\r\n0\ts2\t\r\n1\t\tacc\r\n2\t\tr7\r\n"}),
+			expectedErr: ErrorUnknownRule,
+		},
+		{
+			name:        "Rule without goto column",
+			files:       tinyLanguage(map[string]string{GotoTableFile: "estado\tT\r\n0\t1\r\n1\t\r\n2\t\r\n"}),
+			expectedErr: ErrorUnknownGotoRule,
+		},
+		{
+			name: "Error rule without code",
+			files: tinyLanguage(map[string]string{GrammarFile: `[
+				{"rule_number": 0, "left": "S'", "right": ["S"]},
+				{"rule_number": 1, "left": "S", "right": ["a"]},
+				{"rule_number": 2, "left": "S", "right": ["error", "a"], "message": "S inválido"}
+			]`}),
+			expectedErr: ErrorIncompleteRule,
+		},
+		{
+			name:        "Missing goto after a reduce",
+			files:       tinyLanguage(map[string]string{GotoTableFile: "estado\tS\r\n0\t\r\n1\t\r\n2\t\r\n"}),
+			expectedErr: ErrorMissingGoto,
+		},
+		{
+			name:        "Tables with different states",
+			files:       tinyLanguage(map[string]string{GotoTableFile: "estado\tS\r\n0\t1\r\n1\t\r\n"}),
+			expectedErr: ErrorTablesMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			language, err := OpenLanguage(tc.files)
+			if tc.expectedErr != nil {
+				r.ErrorIs(err, tc.expectedErr)
+				return
+			}
+			r.NoError(err)
+			r.Equal("S", language.Rules.GetRule(1).Left)
+			action, opr := language.Action.GetAction(0, lexer.NewToken("a", "a", lexer.NULL))
+			r.Equal(SHIFT, action)
+			r.Equal(2, opr)
+			action, opr = language.Action.GetAction(2, lexer.NewToken(lexer.EOF, "", lexer.NULL))
+			r.Equal(REDUCE, action)
+			r.Equal(1, opr)
+			r.Equal(1, language.GotoAfter(0, 1))
+		})
+	}
+}
+
+func TestGetDefaultLanguage(t *testing.T) {
+	r := require.New(t)
+	language := GetDefaultLanguage()
+	r.Same(language, GetDefaultLanguage())
+	r.Equal("P", language.Rules.GetRule(1).Left)
+	action, _ := language.Action.GetAction(1, lexer.NewToken(lexer.EOF, "", lexer.NULL))
+	r.Equal(ACCEPT, action)
+	r.Equal(1, language.Goto.GetGoto(0, "P"))
+}
@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"mgol-go/src/lexer"
+	"mgol-go/src/source"
+)
+
+// ParseTree is a node of the derivation of the program by
+// the rules of the grammar. Its leaves are the tokens read,
+// along with the error symbol wherever the error recovery
+// threw away part of the program
+type ParseTree struct {
+	Symbol   string
+	Lexem    string
+	Span     source.Span
+	Children []*ParseTree
+}
+
+// leafOf returns the leaf of the tree for token
+func leafOf(token lexer.Token) *ParseTree {
+	return &ParseTree{
+		Symbol: token.GetClass(),
+		Lexem:  token.GetLexem(),
+		Span:   token.GetSpan(),
+	}
+}
+
+// reduceParseTree replaces the nodes of the symbols on the
+// right side of rule, read from span, by a node of its left
+// side that has them as children
+func (p *Parser) reduceParseTree(rule Rule, span source.Span) {
+	children := make([]*ParseTree, len(rule.Right))
+	for idx := len(children) - 1; idx >= 0; idx-- {
+		child, _ := p.parseTreeStack.Pop()
+		children[idx], _ = child.(*ParseTree)
+	}
+
+	p.parseTreeStack.Push(&ParseTree{
+		Symbol:   rule.Left,
+		Span:     span,
+		Children: children,
+	})
+}
+
+// ParseTree returns the derivation of the program read by
+// Parse, or nil when the parser couldn't get to its end
+func (p *Parser) ParseTree() *ParseTree {
+	return p.parseTree
+}
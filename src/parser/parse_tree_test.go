@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// describeDerivation writes tree as an s-expression,
+// where leaves are written by their symbol
+func describeDerivation(tree *ParseTree) string {
+	if len(tree.Children) == 0 && tree.Lexem != "" || tree.Symbol == "error" {
+		return tree.Symbol
+	}
+	children := []string{tree.Symbol}
+	for _, child := range tree.Children {
+		children = append(children, describeDerivation(child))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(children, " "))
+}
+
+func TestParseTree(t *testing.T) {
+	testCases := []struct {
+		name         string
+		program      string
+		expectedTree string
+	}{
+		{
+			name:         "Empty program",
+			program:      "inicio varinicio varfim; fim",
+			expectedTree: "(P inicio (V varinicio (LV varfim pt_v)) (A fim))",
+		},
+		{
+			name:    "Assignment",
+			program: "inicio varinicio inteiro A; varfim; A <- A + 1; fim",
+			expectedTree: "(P inicio (V varinicio (LV (D (TIPO inteiro) (L id) pt_v) (LV varfim pt_v))) " +
+				"(A (CMD id rcb (LD (LD (TERMO (FATOR (OPRD id)))) opm (TERMO (FATOR (OPRD num)))) pt_v) (A fim)))",
+		},
+		{
+			name:         "Error rule",
+			program:      "inicio varinicio inteiro A; varfim; A <- ; fim",
+			expectedTree: "(P inicio (V varinicio (LV (D (TIPO inteiro) (L id) pt_v) (LV varfim pt_v))) (A (CMD error pt_v) (A fim)))",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			parser := newTestParser(t, tc.program)
+			parser.Parse()
+			r.NotNil(parser.ParseTree())
+			r.Equal(tc.expectedTree, describeDerivation(parser.ParseTree()))
+		})
+	}
+}
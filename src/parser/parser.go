@@ -2,18 +2,25 @@ package parser
 
 import (
 	"fmt"
-	"log"
+	"mgol-go/src/ast"
+	errorhandling "mgol-go/src/error_handling"
+	"mgol-go/src/grammar"
 	"mgol-go/src/lexer"
+	"mgol-go/src/source"
 	"mgol-go/src/stack"
 )
 
 var (
-	tokensToIgnore = []lexer.Token{
-		lexer.ERROR_TOKEN,
-		lexer.COMMENT_TOKEN,
+	tokensToIgnore = []lexer.TokenClass{
+		lexer.ERROR,
+		lexer.COMMENT,
 	}
 )
 
+// unexpectedTokenError is the category of
+// the errors that have no better message
+const unexpectedTokenError = 1
+
 var errorsMessage = map[int]string{
 	1: "token inesperado",
 	2: "declaração de variáveis mal formada",
@@ -26,48 +33,261 @@ var errorsMessage = map[int]string{
 	9: "parênteses desbalanceados",
 }
 
-var parserErrorFlag = false
+// ErrorCategories tells the table generator which of the
+// errorsMessage goes on each error cell of the action table
+var ErrorCategories = grammar.ErrorCategories{
+	Default: unexpectedTokenError,
+	ByNonterminal: map[string]int{
+		"D":     2,
+		"L":     2,
+		"TIPO":  2,
+		"CAB":   4,
+		"CABR":  5,
+		"CMD":   6,
+		"LD":    7,
+		"OPRD":  7,
+		"EXP_R": 7,
+		"EXP_L": 7,
+		"EXP_E": 7,
+		"EXP_N": 7,
+		"IDX":   7,
+		"DIM":   2,
+		"TERMO": 7,
+		"FATOR": 7,
+		"ES":    8,
+		"ARG":   8,
+	},
+	ByTerminal: map[string]int{
+		"varinicio": 3,
+		"varfim":    3,
+		"inteiro":   3,
+		"real":      3,
+		"literal":   3,
+		"rcb":       6,
+		"vir":       2,
+		"opm":       7,
+		"opr":       7,
+		"opmul":     7,
+		"e":         7,
+		"ou":        7,
+		"nao":       7,
+		"leia":      8,
+		"escreva":   8,
+	},
+}
 
 type Parser struct {
-	scanner         *lexer.Scanner
-	stack           *stack.Stack
-	rules           *RulesMap
-	semantic        *Semantic
-	actionTablePath string
-	gotoTablePath   string
+	scanner   *lexer.Scanner
+	stack     *stack.Stack
+	spanStack *stack.Stack
+	// valueStack holds, for each symbol on the stack,
+	// its token or the part of the tree built for it
+	valueStack *stack.Stack
+	// parseTreeStack holds the
+	// derivation of each symbol
+	parseTreeStack *stack.Stack
+	parseTree      *ParseTree
+	// recorder, when set, gets every step made
+	recorder    *TraceRecorder
+	language    *Language
+	semantic    *Semantic
+	diagnostics *errorhandling.Collector
+	// pending holds the tokens read ahead
+	// of the one the parser is on
+	pending []lexer.Token
+	// pendingError is the syntax error the parser
+	// is recovering from with an error rule
+	pendingError *pendingError
+}
+
+// pendingError is a syntax error whose report waits for
+// the error rule the parser recovers with, since the rule
+// may tell better what went wrong
+type pendingError struct {
+	diagnostic errorhandling.Diagnostic
+	found      string
 }
 
-func NewParser(scanner *lexer.Scanner, stack *stack.Stack, rules *RulesMap, actionTablePath, gotoTablePath string) *Parser {
+func NewParser(scanner *lexer.Scanner, stack *stack.Stack, language *Language) *Parser {
 	return &Parser{
-		scanner:         scanner,
-		stack:           stack,
-		rules:           rules,
-		actionTablePath: actionTablePath,
-		gotoTablePath:   gotoTablePath,
-		semantic:        NewSemantic(scanner.GetSymbolTable()),
+		scanner:     scanner,
+		stack:       stack,
+		language:    language,
+		semantic:    NewSemantic(scanner.GetSymbolTable(), scanner.GetDiagnostics()),
+		diagnostics: scanner.GetDiagnostics(),
 	}
 }
 
 // isInTokensToIgnore return whether a token
 // t is in the list of tokens to ignore or not
 func isInTokensToIgnore(t lexer.Token) bool {
-	for _, token := range tokensToIgnore {
-		if t == token {
+	for _, class := range tokensToIgnore {
+		if t.IsClass(class) {
 			return true
 		}
 	}
 	return false
 }
 
-func (p *Parser) Parse() {
-	token, line, column := p.scanner.Scan()
+// reportPendingError reports the syntax error the parser
+// is recovering from, if any. When rule, the error rule
+// the parser recovered with, is given, its message and
+// code replace the ones of the error category
+func (p *Parser) reportPendingError(rule *Rule) {
+	if p.pendingError == nil {
+		return
+	}
+	diagnostic := p.pendingError.diagnostic
+	if rule != nil {
+		diagnostic.Code = errorhandling.Code(rule.Code)
+		diagnostic.Message = fmt.Sprintf("%s, %s", rule.Message, p.pendingError.found)
+	}
+	p.diagnostics.Report(diagnostic)
+	p.pendingError = nil
+}
+
+// scan returns the next token of the input
+// that matters to the parser
+func (p *Parser) scan() lexer.Token {
+	token := p.scanner.Scan()
 	for isInTokensToIgnore(token) {
-		token, line, column = p.scanner.Scan()
+		token = p.scanner.Scan()
+	}
+	if p.recorder != nil {
+		p.recorder.tokens = append(p.recorder.tokens, token)
+	}
+	return token
+}
+
+// next returns the token after the one the parser is on,
+// taking first the ones that were read ahead
+func (p *Parser) next() lexer.Token {
+	if len(p.pending) > 0 {
+		token := p.pending[0]
+		p.pending = p.pending[1:]
+		return token
 	}
+	return p.scan()
+}
+
+// peek returns, without consuming them, the n tokens after
+// the one the parser is on, or fewer if the input ends
+func (p *Parser) peek(n int) []lexer.Token {
+	for len(p.pending) < n {
+		if len(p.pending) > 0 && p.pending[len(p.pending)-1].IsClass(lexer.EOF) {
+			break
+		}
+		p.pending = append(p.pending, p.scan())
+	}
+	if n > len(p.pending) {
+		n = len(p.pending)
+	}
+	return append([]lexer.Token{}, p.pending[:n]...)
+}
+
+// reducedSpan pops the spans of the n symbols on top of the
+// span stack and returns the span that covers all of them.
+// Empty productions get an empty span right before lookahead
+func (p *Parser) reducedSpan(n int, lookahead lexer.Token) source.Span {
+	if n == 0 {
+		start := lookahead.GetSpan().Start
+		return source.NewSpan(start, start)
+	}
+
+	rawLast, _ := p.spanStack.Pop()
+	first := rawLast.(source.Span)
+	last := first
+	for i := 1; i < n; i++ {
+		rawFirst, _ := p.spanStack.Pop()
+		first = rawFirst.(source.Span)
+	}
+	return source.MergeSpans(first, last)
+}
+
+// savedStacks is a copy of the stacks the parser keeps in
+// lockstep, with one element for each symbol it has read
+type savedStacks struct {
+	states   *stack.Stack
+	spans    *stack.Stack
+	values   *stack.Stack
+	trees    *stack.Stack
+	semantic *stack.Stack
+}
+
+// saveStacks copies the stacks, so the
+// error recovery can give up on its changes
+func (p *Parser) saveStacks() savedStacks {
+	return savedStacks{
+		states:   p.stack.Clone(),
+		spans:    p.spanStack.Clone(),
+		values:   p.valueStack.Clone(),
+		trees:    p.parseTreeStack.Clone(),
+		semantic: p.semantic.semanticStack.Clone(),
+	}
+}
+
+// restoreStacks puts back the stacks as they were when
+// saved. The copy is kept, so it can be restored again
+func (p *Parser) restoreStacks(saved savedStacks) {
+	p.stack = saved.states.Clone()
+	p.spanStack = saved.spans.Clone()
+	p.valueStack = saved.values.Clone()
+	p.parseTreeStack = saved.trees.Clone()
+	p.semantic.semanticStack = saved.semantic.Clone()
+}
+
+// stackStates returns the states on the
+// stack, from the bottom to the top
+func (p *Parser) stackStates() []int {
+	states := []int{}
+	for _, element := range p.stack.Elements() {
+		states = append(states, element.(int))
+	}
+	return states
+}
+
+// pushSymbol pushes the state reached by shifting token
+func (p *Parser) pushSymbol(state int, token lexer.Token) {
+	p.stack.Push(state)
+	p.spanStack.Push(token.GetSpan())
+	p.valueStack.Push(token)
+	p.parseTreeStack.Push(leafOf(token))
+	p.semantic.Shift(token)
+}
+
+// popSymbol pops the symbol on top of the stacks
+func (p *Parser) popSymbol() {
+	p.stack.Pop()
+	p.spanStack.Pop()
+	p.valueStack.Pop()
+	p.parseTreeStack.Pop()
+	p.semantic.semanticStack.Pop()
+}
+
+// Parse reads the whole program, translating it to C when
+// no errors are found. It returns the abstract syntax tree
+// of the program, or nil when it has syntax errors, and
+// every diagnostic reported by the compilation sorted by
+// where they happen
+func (p *Parser) Parse() (*ast.Program, []errorhandling.Diagnostic) {
+	token := p.next()
+	p.spanStack = stack.NewStack(p.stack.GetCapacity())
+	p.valueStack = stack.NewStack(p.stack.GetCapacity())
+	p.parseTreeStack = stack.NewStack(p.stack.GetCapacity())
+	p.parseTree = nil
 	p.stack.Push(0)
+	p.spanStack.Push(source.Span{})
+	p.valueStack.Push(nil)
+	p.parseTreeStack.Push(nil)
+	p.semantic.Shift(placeholder(source.Span{}))
 
-	actionReader := NewActionReader(p.actionTablePath)
-	gotoReader := NewGotoReader(p.gotoTablePath)
+	actionReader := p.language.Action
+	terminal := actionReader.GetTerminal(token)
+	// previous is the last token shifted, which
+	// may be the cause of a syntax error
+	var previous lexer.Token
+	var program *ast.Program
+	syntaxError := false
 	for {
 		topStack, err := p.stack.Get()
 		if err != nil {
@@ -75,36 +295,74 @@ func (p *Parser) Parse() {
 		}
 
 		state := lexer.State(topStack.(int))
-		action, opr := actionReader.GetAction(state, token)
+		action, opr := actionReader.Lookup(state, terminal)
 		switch action {
 		case SHIFT:
-			p.stack.Push(opr)
-			p.semantic.semanticStack.Push(token)
-			token, line, column = p.scanner.Scan()
-			for isInTokensToIgnore(token) {
-				token, line, column = p.scanner.Scan()
-			}
+			p.record(TraceShift, token, "empilha %d", opr)
+			p.pushSymbol(opr, token)
+			previous = token
+			token = p.next()
+			terminal = actionReader.GetTerminal(token)
 		case REDUCE:
-			rule := p.rules.GetRule(opr)
-			fmt.Printf("%s -> %s\n", rule.Left, rule.Right)
+			rule := p.language.Rules.GetRule(opr)
+			p.record(TraceReduce, token, "reduz por %s", describeRule(rule))
 			for range rule.Right {
 				p.stack.Pop()
 			}
+			span := p.reducedSpan(len(rule.Right), token)
 			currentTopElement, err := p.stack.Get()
 			state = lexer.State(currentTopElement.(int))
 			if err != nil {
 				panic(err)
 			}
-			gotoOpr := gotoReader.GetGoto(state, rule.Left)
+			gotoOpr := p.language.GotoAfter(state, opr)
+			p.reduceTree(rule, span)
+			p.reduceParseTree(rule, span)
+			p.record(TraceGoto, token, "desvio(%d, %s) = %d", state, rule.Left, gotoOpr)
 			p.stack.Push(gotoOpr)
-			p.semantic.ExecuteRule(rule, line, column)
+			p.spanStack.Push(span)
+			p.semantic.ExecuteRule(rule, span)
+			if rule.HasErrorSymbol() {
+				p.reportPendingError(&rule)
+			}
 		case ACCEPT:
+			p.record(TraceAccept, token, "aceita")
+			tree, _ := p.parseTreeStack.Get()
+			p.parseTree, _ = tree.(*ParseTree)
+			if !syntaxError {
+				value, _ := p.valueStack.Get()
+				program, _ = value.(*ast.Program)
+			}
 			goto end_for
 		case ERROR:
+			syntaxError = true
+			// Empty cells of the table have no category
+			if opr == 0 {
+				opr = unexpectedTokenError
+			}
+			p.reportPendingError(nil)
 			errorMessage := getErrorMessage(opr)
-			log.Printf("Erro: %v na linha %v, coluna %v", errorMessage, line, column)
-			parserErrorFlag = true
-			recoveryStatus := panicMode(p, token)
+			p.record(TraceError, token, "erro: %s", errorMessage)
+			found := expectedMessage(token, p.expectedTerminals(p.stackStates()))
+
+			// Changing a single token is tried first, since
+			// panic mode may throw away whole commands
+			if repair, ok := p.findRepair(token, previous); ok {
+				description := repair.describe(token, previous)
+				repaired, span := repair.apply(p, token, previous)
+				p.record(TraceRecovery, repaired, "%s", description)
+				diagnostic := errorhandling.NewDiagnostic(errorhandling.SyntaxErrorCode(opr), span, "%s, %s", errorMessage, description).WithNote("%s", found)
+				p.diagnostics.Report(withKeywordSuggestion(diagnostic, token, previous))
+				token = repaired
+				terminal = actionReader.GetTerminal(token)
+				continue
+			}
+
+			diagnostic := errorhandling.NewDiagnostic(errorhandling.SyntaxErrorCode(opr), token.GetSpan(), "%s, %s", errorMessage, found)
+			p.pendingError = &pendingError{diagnostic: withKeywordSuggestion(diagnostic, token, previous), found: found}
+			var recoveryStatus RecoveryStatus
+			token, recoveryStatus = panicMode(p, token)
+			terminal = actionReader.GetTerminal(token)
 
 			if recoveryStatus == recoveryFail {
 				goto end_for
@@ -112,10 +370,12 @@ func (p *Parser) Parse() {
 		}
 	}
 end_for:
-	if semanticErrorFlag == false && parserErrorFlag == false {
+	p.reportPendingError(nil)
+	if !p.diagnostics.HasErrors() {
 		p.semantic.GenerateCode()
 	}
 	// p.semantic.symbolTable.Print()
+	return program, p.diagnostics.Sorted()
 }
 
 func getErrorMessage(id int) string {
@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"fmt"
+	"mgol-go/src/lexer"
+	"mgol-go/src/source"
+)
+
+// repairWindow is how many tokens after the one the
+// error happened on a repair must let the parser read
+const repairWindow = 3
+
+type repairKind int
+
+const (
+	insertion repairKind = iota
+	deletion
+	replacement
+)
+
+// repair is a change of a single token on the input,
+// at the token where a syntax error happened
+type repair struct {
+	kind repairKind
+	// token is the one inserted or put in place
+	// of the wrong one, unused by deletions
+	token lexer.Token
+}
+
+// insertableTokens are the tokens a repair may put on the
+// input, by their terminal. Identifiers, numbers, literals
+// and operators are left out, since there is no telling
+// which one the student meant. That includes the logical
+// operators, even though they are reserved words
+var insertableTokens = func() map[string]lexer.Token {
+	operators := map[string]bool{"e": true, "ou": true, "nao": true}
+	tokens := map[string]lexer.Token{}
+	for _, token := range append([]lexer.Token{
+		lexer.ATTR_TOKEN,
+		lexer.OPEN_PAR_TOKEN,
+		lexer.CLOSE_PAR_TOKEN,
+		lexer.SEMICOLON_TOKEN,
+	}, lexer.LanguageReservedTokens...) {
+		if !operators[token.GetClass()] {
+			tokens[token.GetClass()] = token
+		}
+	}
+	return tokens
+}()
+
+// simulate runs the tables from the states over tokens,
+// leaving the parser untouched, and returns how many of
+// the tokens are shifted before an error. Every token
+// counts as shifted when the program is accepted
+func (p *Parser) simulate(states []int, tokens []lexer.Token) int {
+	states = append([]int{}, states...)
+	actionReader := p.language.Action
+	for idx := 0; idx < len(tokens); {
+		state := lexer.State(states[len(states)-1])
+		action, opr := actionReader.Lookup(state, actionReader.GetTerminal(tokens[idx]))
+		switch action {
+		case SHIFT:
+			states = append(states, opr)
+			idx++
+		case REDUCE:
+			states = states[:len(states)-len(p.language.Rules.GetRule(opr).Right)]
+			states = append(states, p.language.GotoAfter(lexer.State(states[len(states)-1]), opr))
+		case ACCEPT:
+			return len(tokens)
+		default:
+			return idx
+		}
+	}
+	return len(tokens)
+}
+
+// findRepair looks for a single token that, inserted
+// before lookahead, deleting it or put in its place, lets
+// the parser read lookahead and the repairWindow tokens
+// after it. Insertions are tried first, then the deletion
+// and then the replacements, taking the first that works.
+// When previous, the token shifted before lookahead, may
+// be a misspelled reserved word, it is not made the target
+// of an assignment, which would ask to declare the typo
+func (p *Parser) findRepair(lookahead, previous lexer.Token) (repair, bool) {
+	states := p.stackStates()
+	upcoming := p.peek(repairWindow)
+	input := append([]lexer.Token{lookahead}, upcoming...)
+
+	candidates := []lexer.Token{}
+	for _, terminal := range p.expectedTerminals(states) {
+		if _, typo := misspelledKeyword(previous); typo && terminal == lexer.ATTR_TOKEN.GetClass() {
+			continue
+		}
+		if token, found := insertableTokens[terminal]; found {
+			candidates = append(candidates, token)
+		}
+	}
+
+	for _, candidate := range candidates {
+		if p.simulate(states, append([]lexer.Token{candidate}, input...)) == len(input)+1 {
+			return repair{kind: insertion, token: candidate}, true
+		}
+	}
+	if lookahead.IsClass(lexer.EOF) {
+		return repair{}, false
+	}
+	if p.simulate(states, upcoming) == len(upcoming) {
+		return repair{kind: deletion}, true
+	}
+	for _, candidate := range candidates {
+		if p.simulate(states, append([]lexer.Token{candidate}, upcoming...)) == len(upcoming)+1 {
+			return repair{kind: replacement, token: candidate}, true
+		}
+	}
+	return repair{}, false
+}
+
+// apply changes the input as r says, where lookahead is
+// the token the error happened on and previous the one
+// shifted before it. It returns the token the parser
+// goes on with and the span of the change
+func (r repair) apply(p *Parser, lookahead, previous lexer.Token) (lexer.Token, source.Span) {
+	switch r.kind {
+	case insertion:
+		at := lookahead.GetSpan().Start
+		if !previous.GetSpan().IsEmpty() {
+			at = previous.GetSpan().End
+		}
+		span := source.NewSpan(at, at)
+		inserted := r.token
+		inserted.SetSpan(span)
+		p.pending = append([]lexer.Token{lookahead}, p.pending...)
+		return inserted, span
+	case replacement:
+		replaced := r.token
+		replaced.SetSpan(lookahead.GetSpan())
+		return replaced, lookahead.GetSpan()
+	}
+	return p.next(), lookahead.GetSpan()
+}
+
+// describe tells the student how the
+// input was changed by r to go on
+func (r repair) describe(lookahead, previous lexer.Token) string {
+	switch r.kind {
+	case insertion:
+		if previous.GetSpan().IsEmpty() {
+			return fmt.Sprintf("inserido %s antes de %s", describeTerminal(r.token.GetClass()), describeToken(lookahead))
+		}
+		return fmt.Sprintf("inserido %s depois de %s", describeTerminal(r.token.GetClass()), describeToken(previous))
+	case replacement:
+		return fmt.Sprintf("trocado %s por %s", describeToken(lookahead), describeTerminal(r.token.GetClass()))
+	}
+	return fmt.Sprintf("removido %s", describeToken(lookahead))
+}
@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepair(t *testing.T) {
+	testCases := []struct {
+		name            string
+		program         string
+		expectedMessage string
+		expectedSpan    string
+	}{
+		{
+			name:            "Missing semicolon",
+			program:         "inicio varinicio inteiro A; varfim; leia A fim",
+			expectedMessage: "operação de entrada e saída inválida, inserido ';' depois de 'A'",
+			expectedSpan:    "1:43-1:43",
+		},
+		{
+			name:            "Missing reserved word",
+			program:         "inicio varinicio inteiro A; varfim; se (A > 1) escreva A; fimse fim",
+			expectedMessage: "estrutura condicional mal formada, inserido 'entao' depois de ')'",
+			expectedSpan:    "1:47-1:47",
+		},
+		{
+			name:            "Extra token",
+			program:         "inicio varinicio inteiro A; varfim; leia A; ) escreva A; fim",
+			expectedMessage: "operação de entrada e saída inválida, removido ')'",
+			expectedSpan:    "1:45-1:46",
+		},
+		{
+			name:            "Wrong token",
+			program:         "inicio varinicio inteiro A; varfim; leia A entao escreva A; fim",
+			expectedMessage: "operação de entrada e saída inválida, trocado 'entao' por ';'",
+			expectedSpan:    "1:44-1:49",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			program, diagnostics := parseProgram(t, tc.program)
+			r.Nil(program)
+			r.Len(diagnostics, 1)
+			r.Equal(tc.expectedMessage, diagnostics[0].Message)
+			r.Equal(tc.expectedSpan, diagnostics[0].Span.String())
+		})
+	}
+}
+
+func TestOperatorsAreNeverInserted(t *testing.T) {
+	r := require.New(t)
+	for _, class := range []string{"e", "ou", "nao"} {
+		r.NotContains(insertableTokens, class)
+	}
+
+	program, diagnostics := parseProgram(t, "inicio varinicio inteiro A; varfim; se (A > 1 A < 3) entao fimse fim")
+	r.Nil(program)
+	r.NotEmpty(diagnostics)
+	for _, diagnostic := range diagnostics {
+		r.NotContains(diagnostic.Message, "inserido 'e'")
+		r.NotContains(diagnostic.Message, "inserido 'ou'")
+		r.NotContains(diagnostic.Message, "inserido 'nao'")
+	}
+}
+
+func TestMisspelledKeywordIsNotAssigned(t *testing.T) {
+	testCases := []struct {
+		name         string
+		program      string
+		expectedHint string
+	}{
+		{
+			name:         "Misspelled escreva",
+			program:      "inicio varinicio inteiro x; varfim; escrva x; fim",
+			expectedHint: "você quis dizer 'escreva' em vez de 'escrva'?",
+		},
+		{
+			name:         "Misspelled leia",
+			program:      "inicio varinicio inteiro x; varfim; leiaa x; fim",
+			expectedHint: "você quis dizer 'leia' em vez de 'leiaa'?",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			program, diagnostics := parseProgram(t, tc.program)
+			r.Nil(program)
+			r.Len(diagnostics, 1)
+			r.NotContains(diagnostics[0].Message, "inserido '<-'")
+			r.Equal(tc.expectedHint, diagnostics[0].Hint)
+		})
+	}
+}
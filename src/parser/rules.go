@@ -1,34 +1,13 @@
 package parser
 
 import (
-	"encoding/json"
-	"io/ioutil"
+	"mgol-go/src/grammar"
 )
 
-type Rule struct {
-	Number int      `json:"rule_number"`
-	Left   string   `json:"left"`
-	Right  []string `json:"right"`
-}
+type Rule = grammar.Rule
 
 type RulesMap map[int]Rule
 
-var rulesMapInstance *RulesMap
-
-func loadGrammarRules(path string) []Rule {
-	file, err := ioutil.ReadFile(path)
-	if err != nil {
-		panic(err)
-	}
-
-	rules := []Rule{}
-	err = json.Unmarshal(file, &rules)
-	if err != nil {
-		panic(err)
-	}
-	return rules
-}
-
 func createMapFromSlice(rules []Rule) *RulesMap {
 	rulesMap := make(RulesMap)
 	for _, rule := range rules {
@@ -37,16 +16,6 @@ func createMapFromSlice(rules []Rule) *RulesMap {
 	return &rulesMap
 }
 
-func GetRulesMap(path string) *RulesMap {
-	if rulesMapInstance == nil {
-		rules := loadGrammarRules(path)
-		rulesMapInstance = createMapFromSlice(rules)
-		return rulesMapInstance
-	} else {
-		return rulesMapInstance
-	}
-}
-
 func (r *RulesMap) GetRule(ruleNumber int) Rule {
 	return (*r)[ruleNumber]
 }
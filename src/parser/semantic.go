@@ -3,9 +3,12 @@ package parser
 import (
 	"fmt"
 	"io/ioutil"
-	"log"
+	errorhandling "mgol-go/src/error_handling"
+	"mgol-go/src/grammar"
 	"mgol-go/src/lexer"
+	"mgol-go/src/source"
 	"mgol-go/src/stack"
+	"strconv"
 )
 
 type TemporalType int
@@ -18,9 +21,22 @@ const (
 
 const maxCapacityStack = 10000
 
-var semanticErrorFlag = false
+// Classes of the tokens after which the code generated is
+// marked, since it is written again or moved later: the
+// condition of a loop is evaluated again at its end, the
+// right operand of e and ou is only evaluated when the
+// left one doesn't decide the result, the body of a senao
+// goes after the else of the C code and the value given
+// to a variable when it is declared goes after the
+// declaration
+const (
+	repitaClass lexer.TokenClass = "repita"
+	andClass    lexer.TokenClass = "e"
+	orClass     lexer.TokenClass = "ou"
+	senaoClass  lexer.TokenClass = "senao"
+)
 
-var repitaEndCode = ""
+var markedClasses = []lexer.TokenClass{repitaClass, andClass, orClass, senaoClass, lexer.ATTR}
 
 type CodeBuffer struct {
 	temporals []TemporalType
@@ -49,300 +65,718 @@ func (c *CodeBuffer) PrintTemporals() string {
 	return temporalCode
 }
 
-var rulesMap = map[int]func(s *Semantic, rule Rule, line int, column int){
+// semanticAction runs the translation of a rule. It gets the
+// values of the symbols on the right side of the rule, tokens
+// whose lexem and type are the attributes of the symbol, and
+// returns the value of the left side
+type semanticAction func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token
+
+var rulesMap = map[int]semanticAction{
 	// D -> TIPO L pt_v
-	6: func(s *Semantic, rule Rule, line int, column int) {
-		s.AddToCodeBuffer(";\n")
+	6: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		s.AddToCodeBuffer(";\n" + s.initializations)
+		s.initializations = ""
+		return s.emptyValue(rule, span)
 	},
 
 	// L -> id
-	7: func(s *Semantic, rule Rule, line int, column int) {
-		identifierToken, _ := s.semanticStack.Pop()
-		identifierTokenConverted := identifierToken.(lexer.Token)
-
-		typeToken, _ := s.semanticStack.Pop()
-		typeTokenConverted := typeToken.(lexer.Token)
-
-		identifierTokenConverted.SetType(typeTokenConverted.GetType())
-		s.symbolTable.Update(identifierTokenConverted.GetLexem(), identifierTokenConverted)
-
-		s.AddToCodeBuffer(identifierTokenConverted.GetLexem())
+	7: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		s.declare(values[0], "")
+		return s.emptyValue(rule, span)
 	},
 
 	// TIPO -> inteiro
-	8: func(s *Semantic, rule Rule, line int, column int) {
-		newToken := lexer.NewToken(lexer.TokenClass(rule.Left), "", lexer.INTEGER)
-		s.semanticStack.Push(newToken)
+	8: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
 		s.AddToCodeBuffer("int ")
+		return s.newValue(rule, span, "", lexer.INTEGER)
 	},
 
 	// TIPO -> real
-	9: func(s *Semantic, rule Rule, line int, column int) {
-		newToken := lexer.NewToken(lexer.TokenClass(rule.Left), "", lexer.REAL)
-		s.semanticStack.Push(newToken)
+	9: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
 		s.AddToCodeBuffer("float ")
+		return s.newValue(rule, span, "", lexer.REAL)
 	},
 
 	// TIPO -> literal
-	10: func(s *Semantic, rule Rule, line int, column int) {
-		newToken := lexer.NewToken(lexer.TokenClass(rule.Left), "", lexer.LITERAL)
-		s.semanticStack.Push(newToken)
+	10: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
 		s.AddToCodeBuffer("literal ")
+		return s.newValue(rule, span, "", lexer.LITERAL)
 	},
 
 	// ES -> leia id pt_v
-	12: func(s *Semantic, rule Rule, line int, column int) {
-		s.semanticStack.Pop() // Remove our pt_v
-		idToken, _ := s.semanticStack.Pop()
-		idTokenConverted := idToken.(lexer.Token)
-		if idTokenConverted.GetType() == lexer.NULL {
-			log.Printf("Erro: variável '%s' não declarada na linha %d, coluna %d\n", idTokenConverted.GetLexem(), line-1, column)
-			semanticErrorFlag = true
-			return
-		}
-		switch idTokenConverted.GetType() {
-		case lexer.INTEGER:
-			s.AddToCodeBuffer(fmt.Sprintf("scanf(\"%%d\", &%s);\n", idTokenConverted.GetLexem()))
-		case lexer.LITERAL:
-			s.AddToCodeBuffer(fmt.Sprintf("scanf(\"%%s\", %s);\n", idTokenConverted.GetLexem()))
-		case lexer.REAL:
-			s.AddToCodeBuffer(fmt.Sprintf("scanf(\"%%lf\", &%s);\n", idTokenConverted.GetLexem()))
+	12: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		if target, ok := s.access(values[1].GetSpan(), values[1], ""); ok {
+			s.read(target)
 		}
+		return s.emptyValue(rule, span)
 	},
 
 	// ES -> escreva ARG pt_v
-	13: func(s *Semantic, rule Rule, line int, column int) {
-		s.semanticStack.Pop() // Remove our pt_v
-		argToken, _ := s.semanticStack.Pop()
-		argTokenConverted := argToken.(lexer.Token)
-		switch argTokenConverted.GetType() {
+	13: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		argToken := values[1]
+		switch argToken.GetType() {
 		case lexer.INTEGER:
-			s.AddToCodeBuffer(fmt.Sprintf("printf(\"%%d\", %s);\n", argTokenConverted.GetLexem()))
+			s.AddToCodeBuffer(fmt.Sprintf("printf(\"%%d\", %s);\n", argToken.GetLexem()))
 		case lexer.LITERAL:
-			s.AddToCodeBuffer(fmt.Sprintf("printf(\"%%s\", %s);\n", argTokenConverted.GetLexem()))
+			s.AddToCodeBuffer(fmt.Sprintf("printf(\"%%s\", %s);\n", argToken.GetLexem()))
 		case lexer.REAL:
-			s.AddToCodeBuffer(fmt.Sprintf("printf(\"%%lf\", %s);\n", argTokenConverted.GetLexem()))
+			s.AddToCodeBuffer(fmt.Sprintf("printf(\"%%lf\", %s);\n", argToken.GetLexem()))
 		}
+		return s.emptyValue(rule, span)
 	},
 
 	// ARG -> lit
-	14: func(s *Semantic, rule Rule, line int, column int) {
-		literalToken, _ := s.semanticStack.Pop()
-		literalTokenConverted := literalToken.(lexer.Token)
-		newToken := lexer.NewToken(lexer.TokenClass(rule.Left), literalTokenConverted.GetLexem(), literalTokenConverted.GetType())
-		s.semanticStack.Push(newToken)
-	},
+	14: passOperand,
 
 	// ARG -> num
-	15: func(s *Semantic, rule Rule, line int, column int) {
-		numToken, _ := s.semanticStack.Pop()
-		numTokenConverted := numToken.(lexer.Token)
-		newToken := lexer.NewToken(lexer.TokenClass(rule.Left), numTokenConverted.GetLexem(), numTokenConverted.GetType())
-		s.semanticStack.Push(newToken)
-	},
+	15: passOperand,
 
 	// ARG -> id
-	16: func(s *Semantic, rule Rule, line int, column int) {
-		idToken, _ := s.semanticStack.Pop()
-		idTokenConverted := idToken.(lexer.Token)
-		if idTokenConverted.GetType() == lexer.NULL {
-			log.Printf("Erro: variável '%s' não declarada na linha %d, coluna %d\n", idTokenConverted.GetLexem(), line-1, column)
-			semanticErrorFlag = true
-			return
-		}
-
-		newToken := lexer.NewToken(lexer.TokenClass(rule.Left), idTokenConverted.GetLexem(), idTokenConverted.GetType())
-		s.semanticStack.Push(newToken)
-	},
+	16: passIdentifier,
 
 	// CMD -> id rcb LD pt_v
-	18: func(s *Semantic, rule Rule, line int, column int) {
-		s.semanticStack.Pop() // remove our pt_v
-		rawLD, _ := s.semanticStack.Pop()
-		LD := rawLD.(lexer.Token)
+	18: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		if target, ok := s.access(values[0].GetSpan(), values[0], ""); ok {
+			s.assign(span, values[0], target, values[2])
+		}
+		return s.emptyValue(rule, span)
+	},
 
-		s.semanticStack.Pop() // remove our rcb
+	// LD -> LD opm TERMO
+	19: arithmetic,
 
-		rawId, _ := s.semanticStack.Pop()
-		id := rawId.(lexer.Token)
+	// LD -> TERMO
+	20: passOperand,
 
-		if id.GetType() == lexer.NULL {
-			log.Printf("Erro: variável '%s' não declarada na linha %d, coluna %d\n", id.GetLexem(), line-1, column)
-			semanticErrorFlag = true
-			return
-		}
+	// OPRD -> id
+	21: passIdentifier,
 
-		if id.GetType() != LD.GetType() && LD.GetType() != lexer.NULL {
-			log.Printf("Erro: Tipos diferentes para a atribuição na linha %d, coluna %d. '%s' é do tipo '%s', enquanto que '%s' é do tipo '%s'\n", line-1, column, id.GetLexem(), id.GetType(), LD.GetLexem(), LD.GetType())
-			semanticErrorFlag = true
-			return
-		}
+	// OPRD -> num
+	22: passOperand,
 
-		s.AddToCodeBuffer(fmt.Sprintf("%s = %s;\n", id.GetLexem(), LD.GetLexem()))
+	// COND -> CAB CP
+	24: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		s.AddToCodeBuffer("}\n")
+		return s.emptyValue(rule, span)
 	},
 
-	// LD -> OPRD opm OPRD
-	19: func(s *Semantic, rule Rule, line int, column int) {
-		rawOprd2, _ := s.semanticStack.Pop()
-		oprd2 := rawOprd2.(lexer.Token)
+	// CAB -> se ab_p EXP_L fc_p entao
+	25: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		if !s.checkCondition(values[0], values[2]) {
+			return s.emptyValue(rule, span)
+		}
+		s.AddToCodeBuffer(fmt.Sprintf("if (%s) {\n", values[2].GetLexem()))
+		return s.emptyValue(rule, span)
+	},
 
-		rawOpm, _ := s.semanticStack.Pop()
-		opm := rawOpm.(lexer.Token)
+	// EXP_R -> LD opr LD
+	26: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		oprd1, opr, oprd2 := values[0], values[1], values[2]
 
-		rawOprd1, _ := s.semanticStack.Pop()
-		oprd1 := rawOprd1.(lexer.Token)
+		// Operands with errors were already reported
+		if oprd1.GetType() == lexer.NULL || oprd2.GetType() == lexer.NULL {
+			return s.emptyValue(rule, span)
+		}
 
-		if oprd1.GetType() != oprd2.GetType() && oprd1.GetType() != lexer.LITERAL && oprd2.GetType() != lexer.LITERAL {
-			log.Printf("Erro: Operandos com tipos incompatíveis na linha %d, coluna %d. '%s' é do tipo '%s', enquanto que '%s' é do tipo '%s'\n", line, column, oprd1.GetLexem(), oprd1.GetType(), oprd2.GetLexem(), oprd2.GetType())
-			semanticErrorFlag = true
-			return
+		if oprd1.GetType() != oprd2.GetType() {
+			s.diagnostics.Report(errorhandling.NewDiagnostic(errorhandling.OperandTypeMismatchCode, span, "operandos com tipos incompatíveis, '%s' é do tipo '%s', enquanto que '%s' é do tipo '%s'", oprd1.GetLexem(), oprd1.GetType(), oprd2.GetLexem(), oprd2.GetType()))
+			return s.emptyValue(rule, span)
 		}
 
-		temporal := ""
-		operationType := lexer.NULL
+		temporalId := s.NewTemporal(TemporalBool)
 
-		switch oprd1.GetType() {
-		case lexer.INTEGER:
-			temporal = s.NewTemporal(TemporalInt)
-			operationType = lexer.INTEGER
-		case lexer.REAL:
-			temporal = s.NewTemporal(TemporalFloat)
-			operationType = lexer.REAL
+		if opr.GetLexem() == "<>" {
+			s.AddToCodeBuffer(fmt.Sprintf("%s = %s < %s || %s > %s;\n", temporalId, oprd1.GetLexem(), oprd2.GetLexem(), oprd1.GetLexem(), oprd2.GetLexem()))
+		} else {
+			s.AddToCodeBuffer(fmt.Sprintf("%s = %s %s %s;\n", temporalId, oprd1.GetLexem(), opr.GetLexem(), oprd2.GetLexem()))
 		}
 
-		s.AddToCodeBuffer(fmt.Sprintf("%s = %s %s %s;\n", temporal, oprd1.GetLexem(), opm.GetLexem(), oprd2.GetLexem()))
-		newToken := lexer.NewToken(lexer.TokenClass(rule.Left), temporal, operationType)
-		s.semanticStack.Push(newToken)
+		return s.newValue(rule, span, temporalId, lexer.BOOLEAN)
 	},
 
-	// LD -> OPRD
-	20: func(s *Semantic, rule Rule, line int, column int) {
-		oprdToken, _ := s.semanticStack.Pop()
-		oprdTokenConverted := oprdToken.(lexer.Token)
-		newToken := lexer.NewToken(lexer.TokenClass(rule.Left), oprdTokenConverted.GetLexem(), oprdTokenConverted.GetType())
-		s.semanticStack.Push(newToken)
+	// R -> CABR CPR
+	32: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		// The condition is evaluated again before the next
+		// iteration, with the code CABR generated for it
+		s.AddToCodeBuffer(values[0].GetLexem() + "}\n")
+		return s.emptyValue(rule, span)
 	},
 
-	// OPRD -> id
-	21: func(s *Semantic, rule Rule, line int, column int) {
-		idToken, _ := s.semanticStack.Pop()
-		idTokenConverted := idToken.(lexer.Token)
-		if idTokenConverted.GetType() == lexer.NULL {
-			log.Printf("Erro: variável '%s' não declarada na linha %d, coluna %d\n", idTokenConverted.GetLexem(), line-1, column)
-			semanticErrorFlag = true
-			return
+	// CABR -> repita ab_p EXP_L fc_p
+	33: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		if !s.checkCondition(values[0], values[2]) {
+			return s.emptyValue(rule, span)
 		}
-		newToken := lexer.NewToken(lexer.TokenClass(rule.Left), idTokenConverted.GetLexem(), idTokenConverted.GetType())
-		s.semanticStack.Push(newToken)
+		conditionCode := s.codeSince(values[0])
+		s.AddToCodeBuffer(fmt.Sprintf("while (%s) {\n", values[2].GetLexem()))
+		return s.newValue(rule, span, conditionCode, lexer.NULL)
 	},
 
-	// OPRD -> num
-	22: func(s *Semantic, rule Rule, line int, column int) {
-		numToken, _ := s.semanticStack.Pop()
-		numTokenConverted := numToken.(lexer.Token)
+	// TERMO -> TERMO opmul FATOR
+	41: arithmetic,
+
+	// TERMO -> FATOR
+	42: passOperand,
+
+	// FATOR -> opm FATOR
+	43: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		operator, operand := values[0], values[1]
+		if operand.GetType() == lexer.NULL {
+			return s.emptyValue(rule, span)
+		}
+		if !isNumber(operand) {
+			s.reportOperand(span, operand, operator, "um número")
+			return s.emptyValue(rule, span)
+		}
+		if operator.GetLexem() == "+" {
+			return s.newValue(rule, span, operand.GetLexem(), operand.GetType())
+		}
 
-		newToken := lexer.NewToken(lexer.TokenClass(rule.Left), numTokenConverted.GetLexem(), numTokenConverted.GetType())
-		s.semanticStack.Push(newToken)
+		temporal := s.numericTemporal(operand.GetType())
+		s.AddToCodeBuffer(fmt.Sprintf("%s = -%s;\n", temporal, operand.GetLexem()))
+		return s.newValue(rule, span, temporal, operand.GetType())
 	},
 
-	// COND -> CAB CP
-	24: func(s *Semantic, rule Rule, line int, column int) {
-		s.AddToCodeBuffer("}\n")
+	// FATOR -> ab_p EXP_L fc_p
+	44: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		return s.newValue(rule, span, values[1].GetLexem(), values[1].GetType())
 	},
 
-	// CAB -> se ab_p EXP_R fc_p entao
-	25: func(s *Semantic, rule Rule, line int, column int) {
-		s.semanticStack.Pop() // remove "entao" from stack
-		s.semanticStack.Pop() // remove "fc_p" from stack
-		rawExp_r, _ := s.semanticStack.Pop()
-		exp_r := rawExp_r.(lexer.Token)
-		s.AddToCodeBuffer(fmt.Sprintf("if (%s) {\n", exp_r.GetLexem()))
+	// FATOR -> OPRD
+	45: passOperand,
+
+	// EXP_L -> EXP_L ou EXP_E
+	46: logical,
+
+	// EXP_L -> EXP_E
+	47: passOperand,
+
+	// EXP_E -> EXP_E e EXP_N
+	48: logical,
+
+	// EXP_E -> EXP_N
+	49: passOperand,
+
+	// EXP_N -> nao EXP_N
+	50: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		operator, operand := values[0], values[1]
+		if !s.checkLogical(span, operand, operator) {
+			return s.emptyValue(rule, span)
+		}
+
+		temporal := s.NewTemporal(TemporalBool)
+		s.AddToCodeBuffer(fmt.Sprintf("%s = !%s;\n", temporal, operand.GetLexem()))
+		return s.newValue(rule, span, temporal, lexer.BOOLEAN)
 	},
 
-	// EXP_R -> OPRD opr OPRD
-	26: func(s *Semantic, rule Rule, line int, column int) {
-		rawOprd2, _ := s.semanticStack.Pop()
-		oprd2 := rawOprd2.(lexer.Token)
+	// EXP_N -> EXP_R
+	51: passOperand,
 
-		rawOpr, _ := s.semanticStack.Pop()
-		opr := rawOpr.(lexer.Token)
+	// EXP_R -> LD
+	52: passOperand,
 
-		rawOprd1, _ := s.semanticStack.Pop()
-		oprd1 := rawOprd1.(lexer.Token)
+	// CP -> senao CPS
+	53: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		// COND closes the else when it closes the if
+		s.AddToCodeBuffer("} else {\n" + s.cutCodeSince(values[0]))
+		return s.emptyValue(rule, span)
+	},
 
-		rawAbp, _ := s.semanticStack.Pop()
-		abp := rawAbp.(lexer.Token)
+	// L -> L vir id
+	58: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		s.declare(values[2], ", ")
+		return s.emptyValue(rule, span)
+	},
 
-		rawSeOrRepita, _ := s.semanticStack.Pop()
-		seOrRepita := rawSeOrRepita.(lexer.Token)
+	// L -> id rcb LD
+	59: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		s.initialize(values[0], values[1], values[2], "")
+		return s.emptyValue(rule, span)
+	},
 
-		s.semanticStack.Push(seOrRepita)
-		s.semanticStack.Push(abp)
+	// L -> L vir id rcb LD
+	60: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		s.initialize(values[2], values[3], values[4], ", ")
+		return s.emptyValue(rule, span)
+	},
 
-		if oprd1.GetType() != oprd2.GetType() {
-			log.Printf("Erro: Operandos com tipos incompatíveis na linha %d, coluna %d. '%s' é do tipo '%s', enquanto que '%s' é do tipo '%s'\n", line, column, oprd1.GetLexem(), oprd1.GetType(), oprd2.GetLexem(), oprd2.GetType())
-			semanticErrorFlag = true
-			return
-		}
+	// L -> id DIM
+	61: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		s.declareArray(values[0], values[1], "")
+		return s.emptyValue(rule, span)
+	},
 
-		temporalId := s.NewTemporal(TemporalBool)
+	// L -> L vir id DIM
+	62: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		s.declareArray(values[2], values[3], ", ")
+		return s.emptyValue(rule, span)
+	},
 
-		exp_rToken := lexer.NewToken(lexer.TokenClass(rule.Left), temporalId, lexer.NULL)
-		s.semanticStack.Push(exp_rToken)
-		updateString := ""
-		if opr.GetLexem() == "<>" {
-			updateString = fmt.Sprintf("%s = %s < %s || %s > %s;\n", temporalId, oprd1.GetLexem(), oprd2.GetLexem(), oprd1.GetLexem(), oprd2.GetLexem())
-			s.AddToCodeBuffer(updateString)
-		} else {
-			s.AddToCodeBuffer(fmt.Sprintf("%s = %s %s %s;\n", temporalId, oprd1.GetLexem(), opr.GetLexem(), oprd2.GetLexem()))
+	// DIM -> ab_c num fc_c
+	63: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		return s.newValue(rule, span, "["+values[1].GetLexem()+"]", s.checkSize(values[1]))
+	},
+
+	// DIM -> DIM ab_c num fc_c
+	64: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		sizes, size := values[0], values[2]
+		sizeType := s.checkSize(size)
+		if sizes.GetType() == lexer.NULL {
+			sizeType = lexer.NULL
 		}
+		return s.newValue(rule, span, sizes.GetLexem()+"["+size.GetLexem()+"]", sizeType)
+	},
 
-		if seOrRepita.GetType() == "repita" {
-			if opr.GetLexem() == "<>" {
-				repitaEndCode = updateString
-			} else {
-				repitaEndCode = fmt.Sprintf("%s = %s %s %s;\n", temporalId, oprd1.GetLexem(), opr.GetLexem(), oprd2.GetLexem())
-			}
+	// ES -> leia id IDX pt_v
+	65: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		id, indexes := values[1], values[2]
+		if indexes.GetType() == lexer.NULL {
+			return s.emptyValue(rule, span)
+		}
+		if target, ok := s.access(source.MergeSpans(id.GetSpan(), indexes.GetSpan()), id, indexes.GetLexem()); ok {
+			s.read(target)
 		}
+		return s.emptyValue(rule, span)
+	},
+
+	// ARG -> id IDX
+	66: passElement,
 
+	// CMD -> id IDX rcb LD pt_v
+	67: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		id, indexes := values[0], values[1]
+		if indexes.GetType() == lexer.NULL {
+			return s.emptyValue(rule, span)
+		}
+		if target, ok := s.access(source.MergeSpans(id.GetSpan(), indexes.GetSpan()), id, indexes.GetLexem()); ok {
+			s.assign(span, id, target, values[3])
+		}
+		return s.emptyValue(rule, span)
 	},
 
-	// R -> CABR CPR
-	32: func(s *Semantic, rule Rule, line int, column int) {
-		s.AddToCodeBuffer(repitaEndCode + "}\n")
+	// OPRD -> id IDX
+	68: passElement,
+
+	// IDX -> ab_c LD fc_c
+	69: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		return s.newValue(rule, span, "["+values[1].GetLexem()+"]", s.checkIndex(values[1]))
 	},
 
-	// CABR -> repita ab_p EXP_R fc_p
-	33: func(s *Semantic, rule Rule, line int, column int) {
-		s.semanticStack.Pop() // remove "fc_p" from stack
-		rawExp_r, _ := s.semanticStack.Pop()
-		exp_r := rawExp_r.(lexer.Token)
-		s.AddToCodeBuffer(fmt.Sprintf("while (%s) {\n", exp_r.GetLexem()))
+	// IDX -> IDX ab_c LD fc_c
+	70: func(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+		indexes, index := values[0], values[2]
+		indexType := s.checkIndex(index)
+		if indexes.GetType() == lexer.NULL {
+			indexType = lexer.NULL
+		}
+		return s.newValue(rule, span, indexes.GetLexem()+"["+index.GetLexem()+"]", indexType)
 	},
 }
 
+// arithmetic generates the code of an operation between
+// two operands, putting the result on a new temporal
+func arithmetic(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+	oprd1, opm, oprd2 := values[0], values[1], values[2]
+
+	// Operands with errors were already reported
+	if oprd1.GetType() == lexer.NULL || oprd2.GetType() == lexer.NULL {
+		return s.emptyValue(rule, span)
+	}
+
+	for _, operand := range []lexer.Token{oprd1, oprd2} {
+		if !isNumber(operand) {
+			s.reportOperand(span, operand, opm, "um número")
+			return s.emptyValue(rule, span)
+		}
+	}
+
+	if oprd1.GetType() != oprd2.GetType() {
+		s.diagnostics.Report(errorhandling.NewDiagnostic(errorhandling.OperandTypeMismatchCode, span, "operandos com tipos incompatíveis, '%s' é do tipo '%s', enquanto que '%s' é do tipo '%s'", oprd1.GetLexem(), oprd1.GetType(), oprd2.GetLexem(), oprd2.GetType()))
+		return s.emptyValue(rule, span)
+	}
+
+	temporal := s.numericTemporal(oprd1.GetType())
+	s.AddToCodeBuffer(fmt.Sprintf("%s = %s %s %s;\n", temporal, oprd1.GetLexem(), opm.GetLexem(), oprd2.GetLexem()))
+	return s.newValue(rule, span, temporal, oprd1.GetType())
+}
+
+// isNumber returns whether operand is an inteiro or a real
+func isNumber(operand lexer.Token) bool {
+	return operand.GetType() == lexer.INTEGER || operand.GetType() == lexer.REAL
+}
+
+// logical generates the code of e and ou, which only
+// evaluates the right operand when the left one doesn't
+// decide the result. The code of the right operand was
+// generated after the operator, so it is moved inside an
+// if that tests the left one
+func logical(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+	oprd1, operator, oprd2 := values[0], values[1], values[2]
+	if !s.checkLogical(span, oprd1, operator) || !s.checkLogical(span, oprd2, operator) {
+		return s.emptyValue(rule, span)
+	}
+
+	rightCode := s.cutCodeSince(operator)
+	temporal := s.NewTemporal(TemporalBool)
+	test := temporal
+	if operator.IsClass(orClass) {
+		test = "!" + temporal
+	}
+
+	s.AddToCodeBuffer(fmt.Sprintf("%s = %s;\nif (%s) {\n%s%s = %s;\n}\n", temporal, oprd1.GetLexem(), test, rightCode, temporal, oprd2.GetLexem()))
+	return s.newValue(rule, span, temporal, lexer.BOOLEAN)
+}
+
+// passOperand gives the left side of rule the
+// lexem and the type of its only symbol
+func passOperand(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+	return s.newValue(rule, span, values[0].GetLexem(), values[0].GetType())
+}
+
+// passIdentifier does the same as passOperand, reporting
+// the identifier if it was not declared or is an array
+func passIdentifier(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+	if _, ok := s.access(values[0].GetSpan(), values[0], ""); !ok {
+		return s.emptyValue(rule, span)
+	}
+	return passOperand(s, rule, span, values)
+}
+
+// passElement gives the left side of rule the element
+// of the array on the first symbol at the indexes on
+// the second one
+func passElement(s *Semantic, rule Rule, span source.Span, values []lexer.Token) lexer.Token {
+	id, indexes := values[0], values[1]
+	if indexes.GetType() == lexer.NULL {
+		return s.emptyValue(rule, span)
+	}
+	element, ok := s.access(span, id, indexes.GetLexem())
+	if !ok {
+		return s.emptyValue(rule, span)
+	}
+	return s.newValue(rule, span, element.GetLexem(), element.GetType())
+}
+
 type Semantic struct {
 	semanticStack *stack.Stack
 	codeBuffer    *CodeBuffer
-	ruleMap       map[int]func(s *Semantic, rule Rule, line int, column int)
-	symbolTable   *lexer.SymbolTable
+	// codeStarts tells where the code generated after each
+	// token of markedClasses starts, by the offset of the
+	// token on the source program
+	codeStarts map[int]int
+	// initializations holds the code that gives the variables
+	// of the declaration being read their first values
+	initializations string
+	// dimensions tells how many indexes each
+	// array takes, by the name of the array
+	dimensions  map[string]int
+	ruleMap     map[int]semanticAction
+	symbolTable *lexer.SymbolTable
+	diagnostics *errorhandling.Collector
 }
 
-func NewSemantic(symbolTable *lexer.SymbolTable) *Semantic {
+func NewSemantic(symbolTable *lexer.SymbolTable, diagnostics *errorhandling.Collector) *Semantic {
 	return &Semantic{
 		semanticStack: stack.NewStack(maxCapacityStack),
 		codeBuffer:    NewCodeBuffer(),
+		codeStarts:    map[int]int{},
+		dimensions:    map[string]int{},
 		ruleMap:       rulesMap,
 		symbolTable:   symbolTable,
+		diagnostics:   diagnostics,
 	}
 }
 
-func (s *Semantic) ExecuteRule(rule Rule, line int, column int) {
-	_, found := s.ruleMap[rule.Number+1]
-	if !found {
+// reportUndeclared reports the use of an
+// identifier that was never declared, suggesting
+// the declared one it may be a typo of
+func (s *Semantic) reportUndeclared(id lexer.Token) {
+	diagnostic := errorhandling.NewDiagnostic(errorhandling.UndeclaredVariableCode, id.GetSpan(), "variável '%s' não declarada", id.GetLexem())
+	if suggestion, found := errorhandling.Suggest(id.GetLexem(), s.symbolTable.GetDeclaredIdentifiers()); found {
+		diagnostic = diagnostic.WithHint("você quis dizer '%s'?", suggestion)
+	} else {
+		diagnostic = diagnostic.WithHint("declare '%s' entre varinicio e varfim", id.GetLexem())
+	}
+	s.diagnostics.Report(diagnostic)
+}
+
+// reportOperand reports that operator got operand,
+// when it expects an operand like expected
+func (s *Semantic) reportOperand(span source.Span, operand, operator lexer.Token, expected string) {
+	s.diagnostics.Report(errorhandling.NewDiagnostic(errorhandling.OperandTypeMismatchCode, span, "operando com tipo incompatível, '%s' é do tipo '%s', enquanto que '%s' espera %s", operand.GetLexem(), operand.GetType(), operator.GetLexem(), expected))
+}
+
+// checkLogical returns whether operand can be used by the
+// logical operator, reporting it when it isn't a logical
+// value. Operands with errors were already reported
+func (s *Semantic) checkLogical(span source.Span, operand, operator lexer.Token) bool {
+	switch operand.GetType() {
+	case lexer.NULL:
+		return false
+	case lexer.BOOLEAN:
+		return true
+	}
+	s.reportOperand(span, operand, operator, "um valor lógico")
+	return false
+}
+
+// checkCondition returns whether condition, read after
+// keyword, is a logical value, reporting it when it isn't.
+// Conditions with errors were already reported
+func (s *Semantic) checkCondition(keyword, condition lexer.Token) bool {
+	switch condition.GetType() {
+	case lexer.NULL:
+		return false
+	case lexer.BOOLEAN:
+		return true
+	}
+	s.diagnostics.Report(errorhandling.NewDiagnostic(errorhandling.ConditionTypeMismatchCode, condition.GetSpan(), "a condição de '%s' deve ser um valor lógico, mas '%s' é do tipo '%s'", keyword.GetLexem(), condition.GetLexem(), condition.GetType()))
+	return false
+}
+
+// declare gives id the type of the declaration it
+// is on, writing it after separator, and returns it
+func (s *Semantic) declare(id lexer.Token, separator string) lexer.Token {
+	// The type comes from TIPO, right below on the stack
+	typeToken := s.below(0)
+
+	id.SetType(typeToken.GetType())
+	s.symbolTable.Update(id.GetLexem(), id)
+
+	s.AddToCodeBuffer(separator + id.GetLexem())
+	return id
+}
+
+// initialize declares id and assigns it the value
+// read after rcb, once the declaration ends
+func (s *Semantic) initialize(id, rcb, value lexer.Token, separator string) {
+	valueCode := s.cutCodeSince(rcb)
+	id = s.declare(id, separator)
+
+	// Values with errors were already reported
+	if value.GetType() == lexer.NULL || id.GetType() == lexer.NULL {
+		return
+	}
+	if id.GetType() != value.GetType() {
+		s.diagnostics.Report(assignmentMismatch(source.MergeSpans(id.GetSpan(), value.GetSpan()), id, value))
+		return
+	}
+	s.initializations += fmt.Sprintf("%s%s = %s;\n", valueCode, id.GetLexem(), value.GetLexem())
+}
+
+// declareArray declares id as an array whose sizes
+// are on sizes, writing it after separator
+func (s *Semantic) declareArray(id, sizes lexer.Token, separator string) {
+	id = s.declare(id, separator)
+	s.dimensions[id.GetLexem()] = countIndexes(sizes.GetLexem())
+	s.AddToCodeBuffer(sizes.GetLexem())
+}
+
+// checkSize returns the type of the size of an array,
+// which is NULL when size isn't a positive integer
+func (s *Semantic) checkSize(size lexer.Token) lexer.DataType {
+	if value, err := strconv.Atoi(size.GetLexem()); err != nil || value <= 0 {
+		s.diagnostics.Report(errorhandling.NewDiagnostic(errorhandling.InvalidArraySizeCode, size.GetSpan(), "tamanho de vetor inválido, '%s' não é um inteiro positivo", size.GetLexem()))
+		return lexer.NULL
+	}
+	return lexer.INTEGER
+}
+
+// checkIndex returns the type of an index of an array,
+// which is NULL when index isn't an integer. Indexes with
+// errors were already reported
+func (s *Semantic) checkIndex(index lexer.Token) lexer.DataType {
+	switch index.GetType() {
+	case lexer.NULL:
+		return lexer.NULL
+	case lexer.INTEGER:
+		return lexer.INTEGER
+	}
+	s.diagnostics.Report(errorhandling.NewDiagnostic(errorhandling.IndexTypeMismatchCode, index.GetSpan(), "índice com tipo incompatível, '%s' é do tipo '%s', enquanto que um índice deve ser inteiro", index.GetLexem(), index.GetType()))
+	return lexer.NULL
+}
+
+// access returns the variable id, read from span, at the
+// indexes of subscript, which is empty for variables that
+// aren't arrays. It reports id when it was not declared or
+// doesn't take as many indexes as subscript has
+func (s *Semantic) access(span source.Span, id lexer.Token, subscript string) (lexer.Token, bool) {
+	if id.GetType() == lexer.NULL {
+		s.reportUndeclared(id)
+		return id, false
+	}
+
+	dimensions, count := s.dimensions[id.GetLexem()], countIndexes(subscript)
+	if dimensions != count {
+		var diagnostic errorhandling.Diagnostic
+		switch {
+		case dimensions == 0:
+			diagnostic = errorhandling.NewDiagnostic(errorhandling.IndexCountMismatchCode, span, "variável '%s' não é um vetor e não pode ser indexada", id.GetLexem())
+		case count == 0:
+			diagnostic = errorhandling.NewDiagnostic(errorhandling.IndexCountMismatchCode, span, "'%s' é um vetor e precisa de %s", id.GetLexem(), describeIndexes(dimensions))
+		default:
+			diagnostic = errorhandling.NewDiagnostic(errorhandling.IndexCountMismatchCode, span, "'%s' precisa de %s, mas foi usada com %s", id.GetLexem(), describeIndexes(dimensions), describeIndexes(count))
+		}
+		s.diagnostics.Report(s.withDeclarationNote(diagnostic, id))
+		return id, false
+	}
+
+	element := lexer.NewToken(lexer.IDENTIFIER, id.GetLexem()+subscript, id.GetType())
+	element.SetSpan(span)
+	return element, true
+}
+
+// read generates the code that reads target from the input
+func (s *Semantic) read(target lexer.Token) {
+	switch target.GetType() {
+	case lexer.INTEGER:
+		s.AddToCodeBuffer(fmt.Sprintf("scanf(\"%%d\", &%s);\n", target.GetLexem()))
+	case lexer.LITERAL:
+		s.AddToCodeBuffer(fmt.Sprintf("scanf(\"%%s\", %s);\n", target.GetLexem()))
+	case lexer.REAL:
+		s.AddToCodeBuffer(fmt.Sprintf("scanf(\"%%lf\", &%s);\n", target.GetLexem()))
+	}
+}
+
+// assign generates the code that stores value on target,
+// which is the variable id or one of its elements
+func (s *Semantic) assign(span source.Span, id, target, value lexer.Token) {
+	// Values with errors were already reported
+	if value.GetType() == lexer.NULL {
+		return
+	}
+	if target.GetType() != value.GetType() {
+		s.diagnostics.Report(s.withDeclarationNote(assignmentMismatch(span, target, value), id))
 		return
 	}
-	s.ruleMap[rule.Number+1](s, rule, line, column)
+	s.AddToCodeBuffer(fmt.Sprintf("%s = %s;\n", target.GetLexem(), value.GetLexem()))
+}
+
+// countIndexes returns how many indexes subscript
+// has, leaving out the ones of the arrays used
+// inside of its indexes
+func countIndexes(subscript string) int {
+	count, depth := 0, 0
+	for _, char := range subscript {
+		switch char {
+		case '[':
+			if depth == 0 {
+				count++
+			}
+			depth++
+		case ']':
+			depth--
+		}
+	}
+	return count
+}
+
+// describeIndexes writes how many indexes there are
+func describeIndexes(count int) string {
+	if count == 1 {
+		return "1 índice"
+	}
+	return fmt.Sprintf("%d índices", count)
+}
+
+// assignmentMismatch is the error of
+// assigning value to a variable of other type
+func assignmentMismatch(span source.Span, id, value lexer.Token) errorhandling.Diagnostic {
+	return errorhandling.NewDiagnostic(errorhandling.AssignmentTypeMismatchCode, span, "tipos diferentes para a atribuição, '%s' é do tipo '%s', enquanto que '%s' é do tipo '%s'", id.GetLexem(), id.GetType(), value.GetLexem(), value.GetType())
+}
+
+// withDeclarationNote adds to diagnostic a note
+// telling where the identifier id was declared
+func (s *Semantic) withDeclarationNote(diagnostic errorhandling.Diagnostic, id lexer.Token) errorhandling.Diagnostic {
+	declaration, err := s.symbolTable.GetToken(id.GetLexem())
+	if err != nil || declaration.GetSpan().IsEmpty() {
+		return diagnostic
+	}
+	start := declaration.GetSpan().Start
+	return diagnostic.WithNote("'%s' foi declarada na linha %d coluna %d", id.GetLexem(), start.Line, start.Column)
+}
+
+// Shift pushes the value of a token read by the parser
+func (s *Semantic) Shift(token lexer.Token) {
+	for _, class := range markedClasses {
+		if token.IsClass(class) {
+			s.codeStarts[token.GetSpan().Start.Offset] = len(s.codeBuffer.code)
+		}
+	}
+	s.semanticStack.Push(token)
+}
+
+// codeSince returns the code generated after
+// the marked token was read
+func (s *Semantic) codeSince(token lexer.Token) string {
+	start, found := s.codeStarts[token.GetSpan().Start.Offset]
+	if !found {
+		return ""
+	}
+	return s.codeBuffer.code[start:]
+}
+
+// cutCodeSince does the same as codeSince, taking
+// the code out of the code buffer
+func (s *Semantic) cutCodeSince(token lexer.Token) string {
+	code := s.codeSince(token)
+	s.codeBuffer.code = s.codeBuffer.code[:len(s.codeBuffer.code)-len(code)]
+	return code
+}
+
+// ExecuteRule runs the semantic action of rule, which
+// reduced the symbols read from span. The values of the
+// symbols are replaced on the stack by the one of the
+// left side, so the stack always has a value for each
+// symbol on the stack of the parser
+func (s *Semantic) ExecuteRule(rule Rule, span source.Span) {
+	values := make([]lexer.Token, len(rule.Right))
+	for idx := len(values) - 1; idx >= 0; idx-- {
+		value, _ := s.semanticStack.Pop()
+		values[idx], _ = value.(lexer.Token)
+	}
+
+	value := s.emptyValue(rule, span)
+	if action, found := s.ruleMap[rule.Number+1]; found {
+		value = action(s, rule, span, values)
+	}
+	s.semanticStack.Push(value)
+}
+
+// below returns the value of the symbol depth positions
+// below the top of the stack. While a rule is reduced,
+// the top is the symbol right before its right side
+func (s *Semantic) below(depth int) lexer.Token {
+	elements := s.semanticStack.Elements()
+	if depth >= len(elements) {
+		return placeholder(source.Span{})
+	}
+	value, _ := elements[len(elements)-1-depth].(lexer.Token)
+	return value
+}
+
+// placeholder is the value of the symbols that have no
+// attributes: the bottom of the stack and the error symbol,
+// which stands for a phrase thrown away by error recovery
+func placeholder(span source.Span) lexer.Token {
+	value := lexer.NewToken(lexer.TokenClass(grammar.ErrorSymbol), "", lexer.NULL)
+	value.SetSpan(span)
+	return value
+}
+
+// newValue returns the value of the left side of
+// rule, read from span, with lexem and dataType
+func (s *Semantic) newValue(rule Rule, span source.Span, lexem string, dataType lexer.DataType) lexer.Token {
+	value := lexer.NewToken(lexer.TokenClass(rule.Left), lexem, dataType)
+	value.SetSpan(span)
+	return value
+}
+
+// emptyValue returns the value of the left side of
+// rule when it has no attributes, or its symbols had
+// errors that were already reported
+func (s *Semantic) emptyValue(rule Rule, span source.Span) lexer.Token {
+	return s.newValue(rule, span, "", lexer.NULL)
 }
 
 func (s *Semantic) AddToCodeBuffer(code string) {
@@ -356,6 +790,15 @@ func (s *Semantic) NewTemporal(temporalType TemporalType) string {
 	return fmt.Sprintf("T%d", temporalId)
 }
 
+// numericTemporal adds a new temporal variable
+// for a value of dataType, an inteiro or a real
+func (s *Semantic) numericTemporal(dataType lexer.DataType) string {
+	if dataType == lexer.REAL {
+		return s.NewTemporal(TemporalFloat)
+	}
+	return s.NewTemporal(TemporalInt)
+}
+
 func (s *Semantic) GenerateCode() {
 	currentCode := `
 #include<stdio.h>
@@ -0,0 +1,342 @@
+package parser
+
+import (
+	"io/ioutil"
+	errorhandling "mgol-go/src/error_handling"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArithmeticTypes(t *testing.T) {
+	testCases := []struct {
+		name            string
+		expression      string
+		expectedMessage string
+	}{
+		{
+			name:            "Literal operand",
+			expression:      "N + 1",
+			expectedMessage: "operando com tipo incompatível, 'N' é do tipo 'literal', enquanto que '+' espera um número",
+		},
+		{
+			name:            "Literal on the right",
+			expression:      "A * N",
+			expectedMessage: "operando com tipo incompatível, 'N' é do tipo 'literal', enquanto que '*' espera um número",
+		},
+		{
+			name:            "Literal operand of unary minus",
+			expression:      "-N",
+			expectedMessage: "operando com tipo incompatível, 'N' é do tipo 'literal', enquanto que '-' espera um número",
+		},
+		{
+			name:            "Literal operand of unary plus",
+			expression:      "+N",
+			expectedMessage: "operando com tipo incompatível, 'N' é do tipo 'literal', enquanto que '+' espera um número",
+		},
+		{
+			name:            "Operands of different types",
+			expression:      "A + 2.5",
+			expectedMessage: "operandos com tipos incompatíveis, 'A' é do tipo 'inteiro', enquanto que '2.5' é do tipo 'real'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			_, diagnostics := parseProgram(t, "inicio varinicio inteiro A; literal N; varfim; A <- "+tc.expression+"; fim")
+			r.Len(diagnostics, 1)
+			r.Equal(errorhandling.OperandTypeMismatchCode, diagnostics[0].Code)
+			r.Equal(tc.expectedMessage, diagnostics[0].Message)
+		})
+	}
+}
+
+func TestLogicalOperatorsShortCircuit(t *testing.T) {
+	testCases := []struct {
+		name         string
+		condition    string
+		expectedCode string
+	}{
+		{
+			name:      "And",
+			condition: "A > 0 e A < 10",
+			expectedCode: `T0 = A > 0;
+T2 = T0;
+if (T2) {
+T1 = A < 10;
+T2 = T1;
+}
+if (T2) {
+`,
+		},
+		{
+			name:      "Or",
+			condition: "A > 0 ou A < 10",
+			expectedCode: `T0 = A > 0;
+T2 = T0;
+if (!T2) {
+T1 = A < 10;
+T2 = T1;
+}
+if (T2) {
+`,
+		},
+		{
+			name:      "Not",
+			condition: "nao A > 0",
+			expectedCode: `T0 = A > 0;
+T1 = !T0;
+if (T1) {
+`,
+		},
+		{
+			name:      "And before or",
+			condition: "A > 0 ou A < 10 e A > 5",
+			expectedCode: `T0 = A > 0;
+T4 = T0;
+if (!T4) {
+T1 = A < 10;
+T3 = T1;
+if (T3) {
+T2 = A > 5;
+T3 = T2;
+}
+T4 = T3;
+}
+if (T4) {
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			parser := newTestParser(t, "inicio varinicio inteiro A; varfim; se ("+tc.condition+") entao fimse fim")
+			_, diagnostics := parser.Parse()
+			r.Empty(diagnostics)
+
+			code, err := ioutil.ReadFile("programa.c")
+			r.NoError(err)
+			r.Contains(string(code), "int A;\n"+tc.expectedCode+"}\n")
+		})
+	}
+}
+
+func TestLogicalOperatorsTypes(t *testing.T) {
+	testCases := []struct {
+		name            string
+		program         string
+		expectedCode    errorhandling.Code
+		expectedMessage string
+	}{
+		{
+			name:            "Number as a condition",
+			program:         "inicio varinicio inteiro A; varfim; se (A) entao fimse fim",
+			expectedCode:    errorhandling.ConditionTypeMismatchCode,
+			expectedMessage: "a condição de 'se' deve ser um valor lógico, mas 'A' é do tipo 'inteiro'",
+		},
+		{
+			name:            "Number as an operand of e",
+			program:         "inicio varinicio inteiro A; varfim; repita (A > 0 e A) fimrepita fim",
+			expectedCode:    errorhandling.OperandTypeMismatchCode,
+			expectedMessage: "operando com tipo incompatível, 'A' é do tipo 'inteiro', enquanto que 'e' espera um valor lógico",
+		},
+		{
+			name:            "Number as an operand of nao",
+			program:         "inicio varinicio inteiro A; varfim; se (nao A) entao fimse fim",
+			expectedCode:    errorhandling.OperandTypeMismatchCode,
+			expectedMessage: "operando com tipo incompatível, 'A' é do tipo 'inteiro', enquanto que 'nao' espera um valor lógico",
+		},
+		{
+			name:            "Comparison as an arithmetic operand",
+			program:         "inicio varinicio inteiro A; varfim; A <- (A > 0) + 1; fim",
+			expectedCode:    errorhandling.OperandTypeMismatchCode,
+			expectedMessage: "operando com tipo incompatível, 'T0' é do tipo 'logico', enquanto que '+' espera um número",
+		},
+		{
+			name:            "Comparison assigned to a number",
+			program:         "inicio varinicio inteiro A; varfim; A <- (A > 0); fim",
+			expectedCode:    errorhandling.AssignmentTypeMismatchCode,
+			expectedMessage: "tipos diferentes para a atribuição, 'A' é do tipo 'inteiro', enquanto que 'T0' é do tipo 'logico'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			_, diagnostics := parseProgram(t, tc.program)
+			r.Len(diagnostics, 1)
+			r.Equal(tc.expectedCode, diagnostics[0].Code)
+			r.Equal(tc.expectedMessage, diagnostics[0].Message)
+		})
+	}
+}
+
+func TestElse(t *testing.T) {
+	r := require.New(t)
+	parser := newTestParser(t, `inicio varinicio inteiro A; varfim;
+		repita (A < 10)
+			se (A > 5) entao
+				escreva A;
+			senao
+				A <- A + 1;
+			fimse
+		fimrepita
+	fim`)
+	_, diagnostics := parser.Parse()
+	r.Empty(diagnostics)
+
+	code, err := ioutil.ReadFile("programa.c")
+	r.NoError(err)
+	r.Contains(string(code), `while (T0) {
+T1 = A > 5;
+if (T1) {
+printf("%d", A);
+} else {
+T2 = A + 1;
+A = T2;
+}
+T0 = A < 10;
+}
+`)
+}
+
+func TestDeclarations(t *testing.T) {
+	testCases := []struct {
+		name             string
+		declarations     string
+		expectedCode     string
+		expectedMessages []string
+	}{
+		{
+			name:         "List",
+			declarations: "inteiro A, B, C;",
+			expectedCode: "int A, B, C;\n",
+		},
+		{
+			name:         "Initializers",
+			declarations: "inteiro A <- 1, B, C <- A * 2; real D <- 2.5;",
+			expectedCode: `int A, B, C;
+A = 1;
+T0 = A * 2;
+C = T0;
+float D;
+D = 2.5;
+`,
+		},
+		{
+			name:             "Initializer of other type",
+			declarations:     "inteiro A, B <- 2.5;",
+			expectedMessages: []string{"tipos diferentes para a atribuição, 'B' é do tipo 'inteiro', enquanto que '2.5' é do tipo 'real'"},
+		},
+		{
+			name:             "Initializer that uses the variable",
+			declarations:     "inteiro A <- A + 1;",
+			expectedMessages: []string{"variável 'A' não declarada"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			parser := newTestParser(t, "inicio varinicio "+tc.declarations+" varfim; fim")
+			_, diagnostics := parser.Parse()
+
+			messages := []string{}
+			for _, diagnostic := range diagnostics {
+				messages = append(messages, diagnostic.Message)
+			}
+			if len(tc.expectedMessages) > 0 {
+				r.Equal(tc.expectedMessages, messages)
+				return
+			}
+			r.Empty(messages)
+
+			code, err := ioutil.ReadFile("programa.c")
+			r.NoError(err)
+			r.Contains(string(code), "------------------------------*/\n"+tc.expectedCode+"\n}")
+		})
+	}
+}
+
+func TestArrays(t *testing.T) {
+	testCases := []struct {
+		name             string
+		program          string
+		expectedCode     string
+		expectedMessages []string
+	}{
+		{
+			name: "Declaration and use",
+			program: `inicio varinicio inteiro V[3], I; real M[2][2]; literal N[2]; varfim;
+				leia V[I];
+				M[0][V[I + 1]] <- M[1][1] * 2.0;
+				leia N[1];
+				escreva N[1];
+			fim`,
+			expectedCode: `int V[3], I;
+float M[2][2];
+literal N[2];
+scanf("%d", &V[I]);
+T0 = I + 1;
+T1 = M[1][1] * 2.0;
+M[0][V[T0]] = T1;
+scanf("%s", N[1]);
+printf("%s", N[1]);
+`,
+		},
+		{
+			name:             "Invalid size",
+			program:          "inicio varinicio inteiro V[2.5]; varfim; fim",
+			expectedMessages: []string{"tamanho de vetor inválido, '2.5' não é um inteiro positivo"},
+		},
+		{
+			name:             "Array without indexes",
+			program:          "inicio varinicio inteiro V[3], I; varfim; I <- V; fim",
+			expectedMessages: []string{"'V' é um vetor e precisa de 1 índice"},
+		},
+		{
+			name:             "Indexed variable that isn't an array",
+			program:          "inicio varinicio inteiro I; varfim; leia I[0]; fim",
+			expectedMessages: []string{"variável 'I' não é um vetor e não pode ser indexada"},
+		},
+		{
+			name:             "Wrong number of indexes",
+			program:          "inicio varinicio real M[2][2]; varfim; escreva M[0]; fim",
+			expectedMessages: []string{"'M' precisa de 2 índices, mas foi usada com 1 índice"},
+		},
+		{
+			name:             "Index that isn't an integer",
+			program:          "inicio varinicio inteiro V[3]; varfim; V[1.5] <- 1; fim",
+			expectedMessages: []string{"índice com tipo incompatível, '1.5' é do tipo 'real', enquanto que um índice deve ser inteiro"},
+		},
+		{
+			name:             "Element of other type",
+			program:          "inicio varinicio inteiro V[3]; varfim; V[0] <- 2.5; fim",
+			expectedMessages: []string{"tipos diferentes para a atribuição, 'V[0]' é do tipo 'inteiro', enquanto que '2.5' é do tipo 'real'"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			parser := newTestParser(t, tc.program)
+			_, diagnostics := parser.Parse()
+
+			messages := []string{}
+			for _, diagnostic := range diagnostics {
+				messages = append(messages, diagnostic.Message)
+			}
+			if len(tc.expectedMessages) > 0 {
+				r.Equal(tc.expectedMessages, messages)
+				return
+			}
+			r.Empty(messages)
+
+			code, err := ioutil.ReadFile("programa.c")
+			r.NoError(err)
+			r.Contains(string(code), "------------------------------*/\n"+tc.expectedCode+"\n}")
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"fmt"
+	"mgol-go/src/lexer"
+	"strings"
+)
+
+// TraceKind is what the parser did on a step
+type TraceKind string
+
+const (
+	TraceShift    TraceKind = "shift"
+	TraceReduce   TraceKind = "reduce"
+	TraceGoto     TraceKind = "goto"
+	TraceAccept   TraceKind = "accept"
+	TraceError    TraceKind = "error"
+	TraceRecovery TraceKind = "recovery"
+)
+
+// TraceStep is a row of the classic table of an LR parse:
+// the stack, with the states and the symbols between them,
+// the input left and the action taken on them
+type TraceStep struct {
+	Kind   TraceKind
+	Stack  []string
+	Input  []string
+	Action string
+}
+
+// recordedStep is a step as it is recorded. The input is
+// only known once the parser has read it, so the step keeps
+// the token the parser was on and how many of the scanned
+// tokens came before the ones it had yet to consume
+type recordedStep struct {
+	kind      TraceKind
+	stack     []string
+	lookahead lexer.Token
+	consumed  int
+	action    string
+}
+
+// TraceRecorder records each step made by a parser,
+// the error recovery included
+type TraceRecorder struct {
+	steps  []recordedStep
+	tokens []lexer.Token
+}
+
+func NewTraceRecorder() *TraceRecorder {
+	return &TraceRecorder{
+		steps:  []recordedStep{},
+		tokens: []lexer.Token{},
+	}
+}
+
+// Steps returns the steps in the order they were made
+func (t *TraceRecorder) Steps() []TraceStep {
+	steps := make([]TraceStep, 0, len(t.steps))
+	for _, step := range t.steps {
+		input := []string{describeInput(step.lookahead)}
+		for _, token := range t.tokens[step.consumed:] {
+			input = append(input, describeInput(token))
+		}
+		steps = append(steps, TraceStep{
+			Kind:   step.kind,
+			Stack:  step.stack,
+			Input:  input,
+			Action: step.action,
+		})
+	}
+	return steps
+}
+
+// describeInput returns how token is shown on the input
+func describeInput(token lexer.Token) string {
+	if token.IsClass(lexer.EOF) {
+		return endOfInput
+	}
+	return token.GetLexem()
+}
+
+// SetTraceRecorder makes the parser record every step it
+// makes on recorder, nil turns the recording off
+func (p *Parser) SetTraceRecorder(recorder *TraceRecorder) {
+	p.recorder = recorder
+}
+
+// record adds a step to the trace, if it is recorded,
+// made while the parser was on the token lookahead
+func (p *Parser) record(kind TraceKind, lookahead lexer.Token, format string, args ...interface{}) {
+	if p.recorder == nil {
+		return
+	}
+	p.recorder.steps = append(p.recorder.steps, recordedStep{
+		kind:      kind,
+		stack:     p.describeStack(),
+		lookahead: lookahead,
+		consumed:  len(p.recorder.tokens) - len(p.pending),
+		action:    fmt.Sprintf(format, args...),
+	})
+}
+
+// describeStack returns the states on the stack with the
+// symbol that led to each one before it. Right after a
+// reduction, the symbol of the left side is on top
+func (p *Parser) describeStack() []string {
+	states := p.stack.Elements()
+	trees := p.parseTreeStack.Elements()
+	stack := []string{}
+	for idx, tree := range trees {
+		if node, ok := tree.(*ParseTree); ok {
+			stack = append(stack, node.Symbol)
+		}
+		if idx < len(states) {
+			stack = append(stack, fmt.Sprint(states[idx]))
+		}
+	}
+	return stack
+}
+
+// describeRule returns rule as it is written on a trace
+func describeRule(rule Rule) string {
+	if len(rule.Right) == 0 {
+		return fmt.Sprintf("%s -> ε", rule.Left)
+	}
+	return fmt.Sprintf("%s -> %s", rule.Left, strings.Join(rule.Right, " "))
+}
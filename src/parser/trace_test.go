@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// describeSteps writes each step as a row of the table
+func describeSteps(steps []TraceStep) []string {
+	rows := []string{}
+	for _, step := range steps {
+		rows = append(rows, fmt.Sprintf("%s | %s | %s", strings.Join(step.Stack, " "), strings.Join(step.Input, " "), step.Action))
+	}
+	return rows
+}
+
+func TestTraceRecorder(t *testing.T) {
+	r := require.New(t)
+	recorder := NewTraceRecorder()
+	parser := newTestParser(t, "inicio varinicio varfim; fim")
+	parser.SetTraceRecorder(recorder)
+	parser.Parse()
+
+	r.Equal([]string{
+		"0 | inicio varinicio varfim ; fim $ | empilha 2",
+		"0 inicio 2 | varinicio varfim ; fim $ | empilha 4",
+		"0 inicio 2 varinicio 4 | varfim ; fim $ | empilha 21",
+		"0 inicio 2 varinicio 4 varfim 21 | ; fim $ | empilha 54",
+		"0 inicio 2 varinicio 4 varfim 21 pt_v 54 | fim $ | reduz por LV -> varfim pt_v",
+		"0 inicio 2 varinicio 4 LV | fim $ | desvio(4, LV) = 19",
+		"0 inicio 2 varinicio 4 LV 19 | fim $ | reduz por V -> varinicio LV",
+		"0 inicio 2 V | fim $ | desvio(2, V) = 3",
+		"0 inicio 2 V 3 | fim $ | empilha 10",
+		"0 inicio 2 V 3 fim 10 | $ | reduz por A -> fim",
+		"0 inicio 2 V 3 A | $ | desvio(3, A) = 5",
+		"0 inicio 2 V 3 A 5 | $ | reduz por P -> inicio V A",
+		"0 P | $ | desvio(0, P) = 1",
+		"0 P 1 | $ | aceita",
+	}, describeSteps(recorder.Steps()))
+}
+
+func TestTraceRecorderWithErrors(t *testing.T) {
+	testCases := []struct {
+		name         string
+		program      string
+		expectedRows []string
+	}{
+		{
+			name:    "Repair",
+			program: "inicio varinicio varfim; leia A fim",
+			expectedRows: []string{
+				"0 inicio 2 V 3 leia 11 id 31 | fim $ | erro: operação de entrada e saída inválida",
+				"0 inicio 2 V 3 leia 11 id 31 | ; fim $ | inserido ';' depois de 'A'",
+				"0 inicio 2 V 3 leia 11 id 31 | ; fim $ | empilha 58",
+			},
+		},
+		{
+			name:    "Error rule",
+			program: "inicio varinicio varfim; A <- ; fim",
+			expectedRows: []string{
+				"0 inicio 2 V 3 id 13 rcb 36 | ; fim $ | erro: tentativa de declaração inválida",
+				"0 inicio 2 V 3 id 13 rcb 36 | ; fim $ | desempilha 36",
+				"0 inicio 2 V 3 id 13 | ; fim $ | desempilha 13",
+				"0 inicio 2 V 3 | ; fim $ | empilha error e vai para 14",
+				"0 inicio 2 V 3 error 14 | ; fim $ | empilha 39",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			recorder := NewTraceRecorder()
+			parser := newTestParser(t, tc.program)
+			parser.SetTraceRecorder(recorder)
+			parser.Parse()
+
+			steps := recorder.Steps()
+			first := 0
+			for first < len(steps) && steps[first].Kind != TraceError {
+				first++
+			}
+			r.Less(first+len(tc.expectedRows), len(steps))
+			r.Equal(tc.expectedRows, describeSteps(steps[first:first+len(tc.expectedRows)]))
+			r.Equal(TraceAccept, steps[len(steps)-1].Kind)
+		})
+	}
+}
@@ -0,0 +1,456 @@
+package parser
+
+import (
+	"mgol-go/src/ast"
+	"mgol-go/src/lexer"
+	"mgol-go/src/source"
+)
+
+// treeActions build the abstract syntax tree while the
+// parser reduces, indexed like rulesMap. Each one gets
+// the values of the symbols on the right side of the rule,
+// the tokens themselves for terminals, and returns the
+// value of the left side
+var treeActions = map[int]func(span source.Span, values []interface{}) interface{}{
+	// P -> inicio V A
+	2: func(span source.Span, values []interface{}) interface{} {
+		declarations, _ := values[1].([]*ast.VarDecl)
+		return &ast.Program{Base: ast.At(span), Declarations: declarations, Body: stmtsOf(values[2])}
+	},
+
+	// V -> varinicio LV
+	3: func(span source.Span, values []interface{}) interface{} {
+		return values[1]
+	},
+
+	// LV -> D LV
+	4: func(span source.Span, values []interface{}) interface{} {
+		return append(declarationsOf(values[0]), declarationsOf(values[1])...)
+	},
+
+	// LV -> varfim pt_v
+	5: func(span source.Span, values []interface{}) interface{} {
+		return []*ast.VarDecl{}
+	},
+
+	// D -> TIPO L pt_v
+	6: func(span source.Span, values []interface{}) interface{} {
+		varType, _ := values[0].(ast.Type)
+		declarations := declarationsOf(values[1])
+		for _, declaration := range declarations {
+			declaration.Type = varType
+		}
+		return declarations
+	},
+
+	// L -> id
+	7: func(span source.Span, values []interface{}) interface{} {
+		return []*ast.VarDecl{{Base: ast.At(span), Name: identOf(values[0])}}
+	},
+
+	// TIPO -> inteiro
+	8: func(span source.Span, values []interface{}) interface{} {
+		return ast.Integer
+	},
+
+	// TIPO -> real
+	9: func(span source.Span, values []interface{}) interface{} {
+		return ast.Real
+	},
+
+	// TIPO -> literal
+	10: func(span source.Span, values []interface{}) interface{} {
+		return ast.Literal
+	},
+
+	// A -> ES A
+	11: prependStmt,
+
+	// ES -> leia id pt_v
+	12: func(span source.Span, values []interface{}) interface{} {
+		return &ast.Read{Base: ast.At(span), Target: identOf(values[1])}
+	},
+
+	// ES -> escreva ARG pt_v
+	13: func(span source.Span, values []interface{}) interface{} {
+		return &ast.Write{Base: ast.At(span), Value: exprOf(values[1])}
+	},
+
+	// ARG -> lit
+	14: operandAction,
+
+	// ARG -> num
+	15: operandAction,
+
+	// ARG -> id
+	16: operandAction,
+
+	// A -> CMD A
+	17: prependStmt,
+
+	// CMD -> id rcb LD pt_v
+	18: func(span source.Span, values []interface{}) interface{} {
+		return &ast.Assign{Base: ast.At(span), Target: identOf(values[0]), Value: exprOf(values[2])}
+	},
+
+	// LD -> LD opm TERMO
+	19: binaryAction,
+
+	// LD -> TERMO
+	20: passValue,
+
+	// OPRD -> id
+	21: operandAction,
+
+	// OPRD -> num
+	22: operandAction,
+
+	// A -> COND A
+	23: prependStmt,
+
+	// COND -> CAB CP
+	24: func(span source.Span, values []interface{}) interface{} {
+		body := branchesOf(values[1])
+		return &ast.If{Base: ast.At(span), Cond: exprOf(values[0]), Body: body.then, Else: body.orElse}
+	},
+
+	// CAB -> se ab_p EXP_L fc_p entao
+	25: func(span source.Span, values []interface{}) interface{} {
+		return values[2]
+	},
+
+	// EXP_R -> LD opr LD
+	26: func(span source.Span, values []interface{}) interface{} {
+		operator, _ := values[1].(lexer.Token)
+		return &ast.RelExpr{Base: ast.At(span), Left: exprOf(values[0]), Operator: operator.GetLexem(), Right: exprOf(values[2])}
+	},
+
+	// CP -> ES CP
+	27: prependBranch,
+
+	// CP -> CMD CP
+	28: prependBranch,
+
+	// CP -> COND CP
+	29: prependBranch,
+
+	// CP -> fimse
+	30: func(span source.Span, values []interface{}) interface{} {
+		return branches{}
+	},
+
+	// A -> R A
+	31: prependStmt,
+
+	// R -> CABR CPR
+	32: func(span source.Span, values []interface{}) interface{} {
+		return &ast.Repeat{Base: ast.At(span), Cond: exprOf(values[0]), Body: stmtsOf(values[1])}
+	},
+
+	// CABR -> repita ab_p EXP_L fc_p
+	33: func(span source.Span, values []interface{}) interface{} {
+		return values[2]
+	},
+
+	// CPR -> ES CPR
+	34: prependStmt,
+
+	// CPR -> CMD CPR
+	35: prependStmt,
+
+	// CPR -> COND CPR
+	36: prependStmt,
+
+	// CPR -> fimrepita
+	37: emptyBody,
+
+	// A -> fim
+	38: emptyBody,
+
+	// TERMO -> TERMO opmul FATOR
+	41: binaryAction,
+
+	// TERMO -> FATOR
+	42: passValue,
+
+	// FATOR -> opm FATOR
+	43: func(span source.Span, values []interface{}) interface{} {
+		operator, _ := values[0].(lexer.Token)
+		return &ast.UnaryExpr{Base: ast.At(span), Operator: operator.GetLexem(), Operand: exprOf(values[1])}
+	},
+
+	// FATOR -> ab_p EXP_L fc_p
+	44: func(span source.Span, values []interface{}) interface{} {
+		return values[1]
+	},
+
+	// FATOR -> OPRD
+	45: passValue,
+
+	// EXP_L -> EXP_L ou EXP_E
+	46: logicalAction,
+
+	// EXP_L -> EXP_E
+	47: passValue,
+
+	// EXP_E -> EXP_E e EXP_N
+	48: logicalAction,
+
+	// EXP_E -> EXP_N
+	49: passValue,
+
+	// EXP_N -> nao EXP_N
+	50: func(span source.Span, values []interface{}) interface{} {
+		operator, _ := values[0].(lexer.Token)
+		return &ast.UnaryExpr{Base: ast.At(span), Operator: operator.GetLexem(), Operand: exprOf(values[1])}
+	},
+
+	// EXP_N -> EXP_R
+	51: passValue,
+
+	// EXP_R -> LD
+	52: passValue,
+
+	// CP -> senao CPS
+	53: func(span source.Span, values []interface{}) interface{} {
+		return branches{orElse: &ast.Else{Base: ast.At(span), Body: stmtsOf(values[1])}}
+	},
+
+	// CPS -> ES CPS
+	54: prependStmt,
+
+	// CPS -> CMD CPS
+	55: prependStmt,
+
+	// CPS -> COND CPS
+	56: prependStmt,
+
+	// CPS -> fimse
+	57: emptyBody,
+
+	// L -> L vir id
+	58: func(span source.Span, values []interface{}) interface{} {
+		return append(declarationsOf(values[0]), &ast.VarDecl{
+			Base: ast.At(declarationSpan(values[2], span)),
+			Name: identOf(values[2]),
+		})
+	},
+
+	// L -> id rcb LD
+	59: func(span source.Span, values []interface{}) interface{} {
+		return []*ast.VarDecl{{Base: ast.At(span), Name: identOf(values[0]), Value: exprOf(values[2])}}
+	},
+
+	// L -> L vir id rcb LD
+	60: func(span source.Span, values []interface{}) interface{} {
+		return append(declarationsOf(values[0]), &ast.VarDecl{
+			Base:  ast.At(declarationSpan(values[2], span)),
+			Name:  identOf(values[2]),
+			Value: exprOf(values[4]),
+		})
+	},
+
+	// L -> id DIM
+	61: func(span source.Span, values []interface{}) interface{} {
+		return []*ast.VarDecl{{Base: ast.At(span), Name: identOf(values[0]), Sizes: sizesOf(values[1])}}
+	},
+
+	// L -> L vir id DIM
+	62: func(span source.Span, values []interface{}) interface{} {
+		return append(declarationsOf(values[0]), &ast.VarDecl{
+			Base:  ast.At(declarationSpan(values[2], span)),
+			Name:  identOf(values[2]),
+			Sizes: sizesOf(values[3]),
+		})
+	},
+
+	// DIM -> ab_c num fc_c
+	63: func(span source.Span, values []interface{}) interface{} {
+		size, _ := values[1].(lexer.Token)
+		return []string{size.GetLexem()}
+	},
+
+	// DIM -> DIM ab_c num fc_c
+	64: func(span source.Span, values []interface{}) interface{} {
+		size, _ := values[2].(lexer.Token)
+		return append(sizesOf(values[0]), size.GetLexem())
+	},
+
+	// ES -> leia id IDX pt_v
+	65: func(span source.Span, values []interface{}) interface{} {
+		return &ast.Read{Base: ast.At(span), Target: elementOf(values[1], values[2])}
+	},
+
+	// ARG -> id IDX
+	66: func(span source.Span, values []interface{}) interface{} {
+		return elementOf(values[0], values[1])
+	},
+
+	// CMD -> id IDX rcb LD pt_v
+	67: func(span source.Span, values []interface{}) interface{} {
+		return &ast.Assign{Base: ast.At(span), Target: elementOf(values[0], values[1]), Value: exprOf(values[3])}
+	},
+
+	// OPRD -> id IDX
+	68: func(span source.Span, values []interface{}) interface{} {
+		return elementOf(values[0], values[1])
+	},
+
+	// IDX -> ab_c LD fc_c
+	69: func(span source.Span, values []interface{}) interface{} {
+		return &ast.IndexExpr{Base: ast.At(span), Indexes: []ast.Expr{exprOf(values[1])}}
+	},
+
+	// IDX -> IDX ab_c LD fc_c
+	70: func(span source.Span, values []interface{}) interface{} {
+		return &ast.IndexExpr{Base: ast.At(span), Indexes: append(indexesOf(values[0]), exprOf(values[2]))}
+	},
+}
+
+// branches is the value of the body of a se: the commands
+// run when the condition holds and its senao, if any
+type branches struct {
+	then   []ast.Stmt
+	orElse *ast.Else
+}
+
+// binaryAction builds the arithmetic operation
+// between the first and the third symbols
+func binaryAction(span source.Span, values []interface{}) interface{} {
+	operator, _ := values[1].(lexer.Token)
+	return &ast.BinaryExpr{Base: ast.At(span), Left: exprOf(values[0]), Operator: operator.GetLexem(), Right: exprOf(values[2])}
+}
+
+// logicalAction builds the logical operation
+// between the first and the third symbols
+func logicalAction(span source.Span, values []interface{}) interface{} {
+	operator, _ := values[1].(lexer.Token)
+	return &ast.LogicalExpr{Base: ast.At(span), Left: exprOf(values[0]), Operator: operator.GetLexem(), Right: exprOf(values[2])}
+}
+
+// passValue gives the left side the
+// value of its only symbol
+func passValue(span source.Span, values []interface{}) interface{} {
+	return values[0]
+}
+
+// prependStmt puts the command on the first
+// symbol before the ones on the second
+func prependStmt(span source.Span, values []interface{}) interface{} {
+	stmt, _ := values[0].(ast.Stmt)
+	return append([]ast.Stmt{stmt}, stmtsOf(values[1])...)
+}
+
+// prependBranch does the same as prependStmt
+// on the commands run when the condition holds
+func prependBranch(span source.Span, values []interface{}) interface{} {
+	stmt, _ := values[0].(ast.Stmt)
+	body := branchesOf(values[1])
+	body.then = append([]ast.Stmt{stmt}, body.then...)
+	return body
+}
+
+// emptyBody is the end of a list of commands
+func emptyBody(span source.Span, values []interface{}) interface{} {
+	return []ast.Stmt{}
+}
+
+// operandAction turns the token of an
+// operand into an identifier or a literal
+func operandAction(span source.Span, values []interface{}) interface{} {
+	token, _ := values[0].(lexer.Token)
+	switch {
+	case token.IsClass(lexer.IDENTIFIER):
+		return identOf(token)
+	case token.IsClass(lexer.LITERAL_CONST):
+		return &ast.StringLit{Base: ast.At(span), Text: token.GetLexem()}
+	case token.GetType() == lexer.REAL:
+		return &ast.RealLit{Base: ast.At(span), Text: token.GetLexem()}
+	}
+	return &ast.IntLit{Base: ast.At(span), Text: token.GetLexem()}
+}
+
+// elementOf returns the element of the array on id
+// at the indexes, built by the rules of IDX
+func elementOf(id interface{}, indexes interface{}) *ast.IndexExpr {
+	array := identOf(id)
+	span := array.Span()
+	if subscript, ok := indexes.(*ast.IndexExpr); ok {
+		span = source.MergeSpans(span, subscript.Span())
+	}
+	return &ast.IndexExpr{Base: ast.At(span), Array: array, Indexes: indexesOf(indexes)}
+}
+
+// declarationSpan returns the span of the declaration
+// that starts at id and ends with span, the one of the
+// rule that declares it after other ones of L
+func declarationSpan(id interface{}, span source.Span) source.Span {
+	if token, ok := id.(lexer.Token); ok {
+		return source.MergeSpans(token.GetSpan(), span)
+	}
+	return span
+}
+
+// identOf returns the identifier a value
+// holds, either as a token or as a node
+func identOf(value interface{}) *ast.Ident {
+	switch v := value.(type) {
+	case *ast.Ident:
+		return v
+	case lexer.Token:
+		return &ast.Ident{Base: ast.At(v.GetSpan()), Name: v.GetLexem()}
+	}
+	return nil
+}
+
+func exprOf(value interface{}) ast.Expr {
+	expr, _ := value.(ast.Expr)
+	return expr
+}
+
+func stmtsOf(value interface{}) []ast.Stmt {
+	stmts, _ := value.([]ast.Stmt)
+	return stmts
+}
+
+// declarationsOf returns the declarations a value holds.
+// The ones of the phrases thrown away by error recovery
+// are left out
+func declarationsOf(value interface{}) []*ast.VarDecl {
+	declarations, _ := value.([]*ast.VarDecl)
+	return declarations
+}
+
+func sizesOf(value interface{}) []string {
+	sizes, _ := value.([]string)
+	return sizes
+}
+
+func indexesOf(value interface{}) []ast.Expr {
+	subscript, ok := value.(*ast.IndexExpr)
+	if !ok {
+		return nil
+	}
+	return subscript.Indexes
+}
+
+func branchesOf(value interface{}) branches {
+	body, _ := value.(branches)
+	return body
+}
+
+// reduceTree replaces the values of the symbols on the
+// right side of rule, read from span, by the value of
+// its left side
+func (p *Parser) reduceTree(rule Rule, span source.Span) {
+	values := make([]interface{}, len(rule.Right))
+	for idx := len(values) - 1; idx >= 0; idx-- {
+		values[idx], _ = p.valueStack.Pop()
+	}
+
+	var value interface{}
+	if action, found := treeActions[rule.Number+1]; found {
+		value = action(span, values)
+	}
+	p.valueStack.Push(value)
+}
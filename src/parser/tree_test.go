@@ -0,0 +1,238 @@
+package parser
+
+import (
+	"fmt"
+	"mgol-go/src/ast"
+	errorhandling "mgol-go/src/error_handling"
+	"mgol-go/src/lexer"
+	"mgol-go/src/stack"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// parseProgram parses program on a temporary directory,
+// where the C code of valid programs is written
+func parseProgram(t *testing.T, program string) (*ast.Program, []errorhandling.Diagnostic) {
+	parser := newTestParser(t, program)
+	return parser.Parse()
+}
+
+// newTestParser returns a parser of program with the default
+// language, whose code is written to a temporary directory
+func newTestParser(t *testing.T, program string) *Parser {
+	r := require.New(t)
+	dir, err := os.Getwd()
+	r.NoError(err)
+	r.NoError(os.Chdir(t.TempDir()))
+	t.Cleanup(func() { os.Chdir(dir) })
+
+	symbolTable := lexer.GetSymbolTableInstance()
+	lexer.FillSymbolTable(symbolTable)
+	t.Cleanup(symbolTable.Cleanup)
+
+	scanner := lexer.NewScanner(strings.NewReader(program), symbolTable, errorhandling.NewCollector())
+	return NewParser(scanner, stack.NewStack(1000), GetDefaultLanguage())
+}
+
+// describe writes node as an s-expression, with its
+// operator or type first, so trees are easy to compare
+func describe(node ast.Node) string {
+	children := []string{}
+	for _, child := range ast.Children(node) {
+		children = append(children, describe(child))
+	}
+
+	head := ""
+	switch n := node.(type) {
+	case *ast.Program:
+		head = "programa"
+	case *ast.VarDecl:
+		head = string(n.Type)
+		for _, size := range n.Sizes {
+			head += "[" + size + "]"
+		}
+	case *ast.Read:
+		head = "leia"
+	case *ast.Write:
+		head = "escreva"
+	case *ast.Assign:
+		head = "<-"
+	case *ast.If:
+		head = "se"
+	case *ast.Else:
+		head = "senao"
+	case *ast.Repeat:
+		head = "repita"
+	case *ast.BinaryExpr:
+		head = n.Operator
+	case *ast.UnaryExpr:
+		head = n.Operator
+	case *ast.RelExpr:
+		head = n.Operator
+	case *ast.LogicalExpr:
+		head = n.Operator
+	case *ast.IndexExpr:
+		head = "[]"
+	case *ast.Ident:
+		return n.Name
+	case *ast.IntLit:
+		return n.Text
+	case *ast.RealLit:
+		return n.Text
+	case *ast.StringLit:
+		return n.Text
+	}
+	return fmt.Sprintf("(%s)", strings.Join(append([]string{head}, children...), " "))
+}
+
+func TestParseBuildsTheTree(t *testing.T) {
+	testCases := []struct {
+		name         string
+		program      string
+		expectedTree string
+	}{
+		{
+			name:         "Empty program",
+			program:      "inicio varinicio varfim; fim",
+			expectedTree: "(programa)",
+		},
+		{
+			name:         "Declarations",
+			program:      "inicio varinicio inteiro A; real B; literal C; varfim; fim",
+			expectedTree: "(programa (inteiro A) (real B) (literal C))",
+		},
+		{
+			name:         "Declaration lists and initializers",
+			program:      "inicio varinicio inteiro A, B <- 1, C <- B * 2; real D <- 2.5; varfim; fim",
+			expectedTree: "(programa (inteiro A) (inteiro B 1) (inteiro C (* B 2)) (real D 2.5))",
+		},
+		{
+			name:         "Reading and writing",
+			program:      "inicio varinicio inteiro A; varfim; leia A; escreva A; escreva \"A vale\"; escreva 2.5; fim",
+			expectedTree: "(programa (inteiro A) (leia A) (escreva A) (escreva \"A vale\") (escreva 2.5))",
+		},
+		{
+			name:         "Assignments",
+			program:      "inicio varinicio inteiro A; varfim; A <- 1; A <- A + 2; fim",
+			expectedTree: "(programa (inteiro A) (<- A 1) (<- A (+ A 2)))",
+		},
+		{
+			name:         "Precedence and associativity",
+			program:      "inicio varinicio inteiro A; varfim; A <- A + 2 * A - 3 / 4 / A; fim",
+			expectedTree: "(programa (inteiro A) (<- A (- (+ A (* 2 A)) (/ (/ 3 4) A))))",
+		},
+		{
+			name:         "Parentheses and unary minus",
+			program:      "inicio varinicio inteiro A; varfim; A <- -(A + 1) * -A; se (A * 2 > -1) entao fimse fim",
+			expectedTree: "(programa (inteiro A) (<- A (* (- (+ A 1)) (- A))) (se (> (* A 2) (- 1))))",
+		},
+		{
+			name:         "Logical operators",
+			program:      "inicio varinicio inteiro A; varfim; se (A > 0 e nao A = 5 ou (A < 0 ou A > 9) e A <> 7) entao fimse fim",
+			expectedTree: "(programa (inteiro A) (se (ou (e (> A 0) (nao (= A 5))) (e (ou (< A 0) (> A 9)) (<> A 7)))))",
+		},
+		{
+			name: "Arrays",
+			program: `inicio varinicio inteiro V[3], I; real M[2][2]; varfim;
+				leia V[I];
+				M[0][V[I + 1]] <- M[1][1] * 2.0;
+				escreva V[0];
+			fim`,
+			expectedTree: "(programa (inteiro[3] V) (inteiro I) (real[2][2] M) (leia ([] V I)) (<- ([] M 0 ([] V (+ I 1))) (* ([] M 1 1) 2.0)) (escreva ([] V 0)))",
+		},
+		{
+			name: "Else",
+			program: `inicio varinicio inteiro A; varfim;
+				se (A > 0) entao
+					escreva A;
+				senao
+					se (A = 0) entao
+						escreva "zero";
+					senao
+						A <- -A;
+					fimse
+					escreva A;
+				fimse
+			fim`,
+			expectedTree: "(programa (inteiro A) (se (> A 0) (escreva A) (senao (se (= A 0) (escreva \"zero\") (senao (<- A (- A)))) (escreva A))))",
+		},
+		{
+			name: "Nested commands",
+			program: `inicio varinicio inteiro A; varfim;
+				repita (A < 10)
+					se (A <> 5) entao
+						escreva A;
+					fimse
+					A <- A + 1;
+				fimrepita
+			fim`,
+			expectedTree: "(programa (inteiro A) (repita (< A 10) (se (<> A 5) (escreva A)) (<- A (+ A 1))))",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			program, diagnostics := parseProgram(t, tc.program)
+			r.Empty(diagnostics)
+			r.NotNil(program)
+			r.Equal(tc.expectedTree, describe(program))
+		})
+	}
+}
+
+func TestParseTreeSpans(t *testing.T) {
+	r := require.New(t)
+	program, diagnostics := parseProgram(t, "inicio varinicio inteiro A; varfim;\nA <- A + 12;\nfim")
+	r.Empty(diagnostics)
+	r.NotNil(program)
+	r.Equal("1:1-3:4", program.Span().String())
+
+	assign := program.Body[0].(*ast.Assign)
+	r.Equal("2:1-2:13", assign.Span().String())
+	r.Equal("2:1-2:2", assign.Target.Span().String())
+	r.Equal("2:6-2:12", assign.Value.Span().String())
+}
+
+func TestParseTreeDeclarationSpans(t *testing.T) {
+	r := require.New(t)
+	program, diagnostics := parseProgram(t, "inicio varinicio inteiro A, B <- 2, C[3];\nreal D; varfim;\nfim")
+	r.Empty(diagnostics)
+	r.NotNil(program)
+
+	spans := []string{}
+	for _, declaration := range program.Declarations {
+		spans = append(spans, declaration.Span().String())
+	}
+	r.Equal([]string{"1:26-1:27", "1:29-1:35", "1:37-1:41", "2:6-2:7"}, spans)
+}
+
+func TestParseWithErrors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		program     string
+		expectsTree bool
+	}{
+		{
+			name:        "Semantic error",
+			program:     "inicio varinicio inteiro A; varfim; leia B; fim",
+			expectsTree: true,
+		},
+		{
+			name:    "Syntax error",
+			program: "inicio varinicio inteiro A; varfim; A <- ; fim",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			program, diagnostics := parseProgram(t, tc.program)
+			r.NotEmpty(diagnostics)
+			r.Equal(tc.expectsTree, program != nil)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+var (
+	ErrorEmptyTable    = fmt.Errorf("the table has no header")
+	ErrorMalformedRow  = fmt.Errorf("malformed row")
+	ErrorMalformedCell = fmt.Errorf("malformed cell")
+	ErrorUnknownState  = fmt.Errorf("unknown state")
+	ErrorMissingColumn = fmt.Errorf("missing column")
+)
+
+// stateColumn names the first column of the tables,
+// which holds the state each row belongs to
+const stateColumn = "estado"
+
+// readTable reads a parser table as tab separated values.
+// The header names the columns and each row starts with
+// its state, numbered from 0 in the order of the rows.
+// It returns the names of the columns and the cells of
+// each row, without the state column
+func readTable(reader io.Reader) ([]string, [][]string, error) {
+	tsv := csv.NewReader(reader)
+	tsv.Comma = '\t'
+
+	records, err := tsv.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 || len(records[0]) == 0 || records[0][0] != stateColumn {
+		return nil, nil, ErrorEmptyTable
+	}
+
+	rows := make([][]string, 0, len(records)-1)
+	for idx, record := range records[1:] {
+		state, err := strconv.Atoi(record[0])
+		if err != nil || state != idx {
+			return nil, nil, fmt.Errorf("%w: line %d starts with %q instead of state %d", ErrorMalformedRow, idx+2, record[0], idx)
+		}
+		rows = append(rows, record[1:])
+	}
+	return records[0][1:], rows, nil
+}
+
+// parseState reads the state a cell of a table with
+// numStates rows leads to
+func parseState(value string, numStates int) (int, error) {
+	state, err := strconv.Atoi(value)
+	if err != nil || state < 0 {
+		return 0, ErrorMalformedCell
+	}
+	if state >= numStates {
+		return 0, ErrorUnknownState
+	}
+	return state, nil
+}
@@ -0,0 +1,58 @@
+// Package source describes locations in the source program,
+// shared by the tokens and the diagnostics about them
+package source
+
+import "fmt"
+
+// Position is a location in the source program. Line and
+// Column start at 1 and Column counts characters, while
+// Offset is the number of bytes that come before it
+type Position struct {
+	Line   int
+	Column int
+	Offset int
+}
+
+// Span is the region of the source program a token or
+// a phrase was read from. End is the position right after
+// its last character, so an empty span has Start == End
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// StartOfFile is the position of the first character
+var StartOfFile = Position{Line: 1, Column: 1, Offset: 0}
+
+// Advance returns the position that comes after reading
+// char, encoded with size bytes, at p
+func (p Position) Advance(char rune, size int) Position {
+	if char == '\n' {
+		return Position{Line: p.Line + 1, Column: 1, Offset: p.Offset + size}
+	}
+	return Position{Line: p.Line, Column: p.Column + 1, Offset: p.Offset + size}
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("linha %d, coluna %d", p.Line, p.Column)
+}
+
+// NewSpan returns the span that goes from start to end
+func NewSpan(start, end Position) Span {
+	return Span{Start: start, End: end}
+}
+
+// MergeSpans returns the smallest span that covers
+// both first and last, which must appear in this order
+func MergeSpans(first, last Span) Span {
+	return Span{Start: first.Start, End: last.End}
+}
+
+// IsEmpty returns whether the span covers no characters
+func (s Span) IsEmpty() bool {
+	return s.Start.Offset == s.End.Offset
+}
+
+func (s Span) String() string {
+	return fmt.Sprintf("%d:%d-%d:%d", s.Start.Line, s.Start.Column, s.End.Line, s.End.Column)
+}
@@ -90,3 +90,9 @@ func (s *Stack) Clone() *Stack {
 
 	return s_copy
 }
+
+//Elements returns a copy of the elements
+//of the stack, from the bottom to the top
+func (s *Stack) Elements() []interface{} {
+	return append([]interface{}{}, s.data...)
+}